@@ -0,0 +1,110 @@
+// Йоу, чат! Зараз розберемо як клієнт розповідає серверу про блоки і предмети!
+// Копання приходить пакетом ServerboundPlayerAction, встановлення блоків -
+// ServerboundUseItemOn, вибір слоту хотбару - ServerboundSetCarriedItem,
+// а в креативі клієнт сам кладе предмети в інвентар пакетом ServerboundSetCreativeModeSlot.
+// Тут ми тільки записуємо все в Inputs, а перевіряє і ламає блоки світ в тіку
+
+package client
+
+import (
+	"FlowyCore/world"
+	"FlowyCore/world/entity"
+	"github.com/Tnze/go-mc/level/block"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// maxQueuedBlockActions - скільки дій одного виду з блоками може чекати на тік
+// В креативі клієнт ламає і ставить блок кожні 4-6 тіків, тому більше - це спам
+const maxQueuedBlockActions = 16
+
+// clientPlayerAction обробляє копання блоків
+func clientPlayerAction(p pk.Packet, c *Client) error {
+	var (
+		Status   pk.VarInt
+		Location pk.Position
+		Face     pk.Byte
+		Sequence pk.VarInt
+	)
+	if err := p.Scan(&Status, &Location, &Face, &Sequence); err != nil {
+		return err
+	}
+	switch world.DigStatus(Status) {
+	case world.DigStart, world.DigCancel, world.DigFinish:
+	default:
+		return nil // викидання предметів і зміна рук поки не підтримуються
+	}
+	c.Inputs.Lock()
+	if len(c.Inputs.Digs) < maxQueuedBlockActions {
+		c.Inputs.Digs = append(c.Inputs.Digs, world.DigAction{
+			Status:   world.DigStatus(Status),
+			Pos:      [3]int32{int32(Location.X), int32(Location.Y), int32(Location.Z)},
+			Sequence: int32(Sequence),
+		})
+	}
+	c.Inputs.Unlock()
+	return nil
+}
+
+// clientUseItemOn обробляє клік предметом по блоку
+func clientUseItemOn(p pk.Packet, c *Client) error {
+	var (
+		Hand                      pk.VarInt
+		Location                  pk.Position
+		Face                      pk.VarInt
+		CursorX, CursorY, CursorZ pk.Float
+		InsideBlock               pk.Boolean
+		Sequence                  pk.VarInt
+	)
+	if err := p.Scan(&Hand, &Location, &Face, &CursorX, &CursorY, &CursorZ, &InsideBlock, &Sequence); err != nil {
+		return err
+	}
+	if Face < 0 || Face > 5 {
+		return nil
+	}
+	c.Inputs.Lock()
+	if len(c.Inputs.Places) < maxQueuedBlockActions {
+		c.Inputs.Places = append(c.Inputs.Places, world.PlaceAction{
+			Hand:     int32(Hand),
+			Pos:      [3]int32{int32(Location.X), int32(Location.Y), int32(Location.Z)},
+			Face:     block.Direction(Face),
+			Cursor:   [3]float32{float32(CursorX), float32(CursorY), float32(CursorZ)},
+			Sequence: int32(Sequence),
+		})
+	}
+	c.Inputs.Unlock()
+	return nil
+}
+
+// clientSetCarriedItem обробляє вибір слоту хотбару
+func clientSetCarriedItem(p pk.Packet, c *Client) error {
+	var Slot pk.Short
+	if err := p.Scan(&Slot); err != nil {
+		return err
+	}
+	if Slot < 0 || Slot > 8 {
+		return nil
+	}
+	c.Inputs.Lock()
+	c.Inputs.HeldItem = int32(Slot)
+	c.Inputs.Unlock()
+	return nil
+}
+
+// clientSetCreativeModeSlot обробляє предмет, який гравець поклав в інвентар в креативі
+func clientSetCreativeModeSlot(p pk.Packet, c *Client) error {
+	var (
+		Slot pk.Short
+		Item entity.Slot
+	)
+	if err := p.Scan(&Slot, &Item); err != nil {
+		return err
+	}
+	c.Inputs.Lock()
+	defer c.Inputs.Unlock()
+	// -1 - предмет викинули з інвентаря
+	if Slot < 0 || int(Slot) >= len(c.Inputs.Inventory) {
+		return nil
+	}
+	c.Inputs.Inventory[Slot] = Item
+	return nil
+}
@@ -159,4 +159,18 @@ var defaultHandlers = [packetid.ServerboundPacketIDGuard]PacketHandler{
 	packetid.ServerboundMovePlayerStatusOnly: clientMovePlayerStatusOnly,
 	// Рух транспорту (поки не реалізовано)
 	packetid.ServerboundMoveVehicle: clientMoveVehicle,
+	// Shift, біг і елітри
+	packetid.ServerboundPlayerCommand: clientPlayerCommand,
+	// Керування транспортом
+	packetid.ServerboundPlayerInput: clientPlayerInput,
+	// Помах рукою
+	packetid.ServerboundSwing: clientSwing,
+	// Копання блоків
+	packetid.ServerboundPlayerAction: clientPlayerAction,
+	// Встановлення блоків
+	packetid.ServerboundUseItemOn: clientUseItemOn,
+	// Вибір слоту хотбару
+	packetid.ServerboundSetCarriedItem: clientSetCarriedItem,
+	// Предмет в інвентарі в креативі
+	packetid.ServerboundSetCreativeModeSlot: clientSetCreativeModeSlot,
 }
@@ -0,0 +1,80 @@
+// Йоу, чат! Зараз розберемо як клієнт каже серверу, що гравець присів чи біжить!
+// Shift, біг і елітри приходять пакетом ServerboundPlayerCommand,
+// керування транспортом - пакетом ServerboundPlayerInput,
+// а помах рукою - пакетом ServerboundSwing.
+// Тут ми тільки записуємо стан в Inputs, а позу і анімації обробляє світ в тіку
+
+package client
+
+import (
+	"FlowyCore/world"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// Дії з ServerboundPlayerCommand
+const (
+	commandPressShiftKey   = 0
+	commandReleaseShiftKey = 1
+	commandStopSleeping    = 2
+	commandStartSprinting  = 3
+	commandStopSprinting   = 4
+	commandStartFallFlying = 8
+)
+
+// clientPlayerCommand обробляє shift, біг і розкриття елітр
+func clientPlayerCommand(p pk.Packet, c *Client) error {
+	var EntityID, Action, JumpBoost pk.VarInt
+	if err := p.Scan(&EntityID, &Action, &JumpBoost); err != nil {
+		return err
+	}
+	c.Inputs.Lock()
+	defer c.Inputs.Unlock()
+	switch Action {
+	case commandPressShiftKey, commandReleaseShiftKey:
+		c.Inputs.Sneaking = Action == commandPressShiftKey
+	case commandStartSprinting, commandStopSprinting:
+		c.Inputs.Sprinting = Action == commandStartSprinting
+	case commandStartFallFlying:
+		c.Inputs.StartFallFlying = true
+	}
+	// Решта дій стосується ліжок і коней, яких поки немає
+	return nil
+}
+
+// clientPlayerInput обробляє керування транспортом
+func clientPlayerInput(p pk.Packet, c *Client) error {
+	var Sideways, Forward pk.Float
+	var Flags pk.UnsignedByte
+	if err := p.Scan(&Sideways, &Forward, &Flags); err != nil {
+		return err
+	}
+	c.Inputs.Lock()
+	c.Inputs.Steer.Sideways = float32(Sideways)
+	c.Inputs.Steer.Forward = float32(Forward)
+	c.Inputs.Steer.Jump = Flags&0x01 != 0
+	c.Inputs.Steer.Unmount = Flags&0x02 != 0
+	c.Inputs.Unlock()
+	return nil
+}
+
+// maxQueuedAnimations - скільки помахів рукою може чекати на тік
+// Більше за тік нормальний клієнт не відправить, а решта - спам
+const maxQueuedAnimations = 8
+
+// clientSwing обробляє помах рукою
+func clientSwing(p pk.Packet, c *Client) error {
+	var Hand pk.VarInt
+	if err := p.Scan(&Hand); err != nil {
+		return err
+	}
+	anim := world.AnimationSwingMainArm
+	if Hand == 1 {
+		anim = world.AnimationSwingOffhand
+	}
+	c.Inputs.Lock()
+	if len(c.Inputs.Animations) < maxQueuedAnimations {
+		c.Inputs.Animations = append(c.Inputs.Animations, anim)
+	}
+	c.Inputs.Unlock()
+	return nil
+}
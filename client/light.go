@@ -0,0 +1,93 @@
+// Йоу, чат! Зараз розберемо як світло їде до клієнта!
+// Світло пишеться масками: біт секції в масці - для неї є масив на 2048 байт,
+// біт в "порожній" масці - в секції всюди 0. Секцій в пакеті на дві більше,
+// ніж у світі: нульовий біт - секція під світом, останній - над світом.
+// go-mc пише маски без цього зсуву, і клієнт кладе світло на секцію нижче,
+// тому чанк з світлом ми пишемо самі.
+
+package client
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/Tnze/go-mc/level"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// fullSky - секція, в якій всюди небесне світло 15
+var fullSky = pk.ByteArray(bytes.Repeat([]byte{0xFF}, 2048))
+
+// chunkLight - світло секцій чанку для ClientboundLevelChunkWithLight і ClientboundLightUpdate
+type chunkLight struct {
+	chunk    *level.Chunk
+	sections uint32 // які секції світу писати (біти)
+	edges    bool   // дописати секції під і над світом, як в пакеті чанку
+}
+
+func (l chunkLight) WriteTo(w io.Writer) (int64, error) {
+	n := len(l.chunk.Sections) + 2
+	skyMask, blockMask := make(pk.BitSet, (n+63)/64), make(pk.BitSet, (n+63)/64)
+	emptySky, emptyBlock := make(pk.BitSet, (n+63)/64), make(pk.BitSet, (n+63)/64)
+	skyLight, blockLight := []pk.ByteArray{}, []pk.ByteArray{}
+	if l.edges {
+		emptySky.Set(0, true)
+		emptyBlock.Set(0, true)
+	}
+	for i, s := range l.chunk.Sections {
+		if l.sections&(1<<i) == 0 {
+			continue
+		}
+		if s.SkyLight != nil {
+			skyMask.Set(i+1, true)
+			skyLight = append(skyLight, s.SkyLight)
+		} else {
+			emptySky.Set(i+1, true)
+		}
+		if s.BlockLight != nil {
+			blockMask.Set(i+1, true)
+			blockLight = append(blockLight, s.BlockLight)
+		} else {
+			emptyBlock.Set(i+1, true)
+		}
+	}
+	if l.edges {
+		skyMask.Set(n-1, true)
+		skyLight = append(skyLight, fullSky)
+		emptyBlock.Set(n-1, true)
+	}
+	return pk.Tuple{
+		pk.Boolean(true), // Trust Edges
+		skyMask,
+		blockMask,
+		emptySky,
+		emptyBlock,
+		pk.Array(skyLight),
+		pk.Array(blockLight),
+	}.WriteTo(w)
+}
+
+// chunkWithLight - чанк разом зі світлом для ClientboundLevelChunkWithLight
+type chunkWithLight struct {
+	*level.Chunk
+}
+
+func (c chunkWithLight) WriteTo(w io.Writer) (int64, error) {
+	data, err := c.Data()
+	if err != nil {
+		return 0, err
+	}
+	return pk.Tuple{
+		// Карти, яких у чанку немає, не пишемо зовсім
+		pk.NBT(struct {
+			MotionBlocking []uint64 `nbt:"MOTION_BLOCKING,omitempty"`
+			WorldSurface   []uint64 `nbt:"WORLD_SURFACE,omitempty"`
+		}{
+			MotionBlocking: c.HeightMaps.MotionBlocking.Raw(),
+			WorldSurface:   c.HeightMaps.WorldSurface.Raw(),
+		}),
+		pk.ByteArray(data),
+		pk.Array(c.BlockEntity),
+		chunkLight{chunk: c.Chunk, sections: 1<<len(c.Sections) - 1, edges: true},
+	}.WriteTo(w)
+}
@@ -26,10 +26,12 @@ import (
 	"go.uber.org/zap"
 
 	"FlowyCore/world"
+	"FlowyCore/world/entity"
 	"github.com/Tnze/go-mc/chat"
 	"github.com/Tnze/go-mc/chat/sign"
 	"github.com/Tnze/go-mc/data/packetid"
 	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/level/block"
 	pk "github.com/Tnze/go-mc/net/packet"
 )
 
@@ -170,7 +172,18 @@ func (c *Client) SendPlayerInfoRemove(players []*world.Player) {
 }
 
 func (c *Client) SendLevelChunkWithLight(pos level.ChunkPos, chunk *level.Chunk) {
-	c.SendPacket(packetid.ClientboundLevelChunkWithLight, pos, chunk)
+	c.SendPacket(packetid.ClientboundLevelChunkWithLight, pos, chunkWithLight{chunk})
+}
+
+// SendLightUpdate відправляє світло секцій чанку, які змінились
+// sections - біти секцій світу, без секції під світом
+func (c *Client) SendLightUpdate(pos level.ChunkPos, chunk *level.Chunk, sections uint32) {
+	c.SendPacket(
+		packetid.ClientboundLightUpdate,
+		pk.VarInt(pos[0]),
+		pk.VarInt(pos[1]),
+		chunkLight{chunk: chunk, sections: sections},
+	)
 }
 
 func (c *Client) SendForgetLevelChunk(pos level.ChunkPos) {
@@ -190,6 +203,28 @@ func (c *Client) SendAddPlayer(p *world.Player) {
 	)
 }
 
+// SendAddEntity показує клієнту сутність, яка не є гравцем
+// Кути передаються в 1/256 оберту в порядку pitch, yaw, а поворот голови дорівнює yaw
+func (c *Client) SendAddEntity(e *world.GenericEntity) {
+	yaw := pk.Angle(world.ToAngle(e.Rotation[0]))
+	c.SendPacket(
+		packetid.ClientboundAddEntity,
+		pk.VarInt(e.EntityID),
+		pk.UUID(e.UUID),
+		pk.VarInt(e.Type.ID),
+		pk.Double(e.Position[0]),
+		pk.Double(e.Position[1]),
+		pk.Double(e.Position[2]),
+		pk.Angle(world.ToAngle(e.Rotation[1])),
+		yaw,
+		yaw, // голова дивиться туди ж, куди тіло
+		pk.VarInt(e.Data),
+		pk.Short(e.Velocity[0]),
+		pk.Short(e.Velocity[1]),
+		pk.Short(e.Velocity[2]),
+	)
+}
+
 // Йоу, чат! Зараз розберемо як працює рух сутностей в майнкрафті!
 // В майні є кілька типів руху - звичайний рух, телепортація і поворот голови
 // Для економії трафіку використовуються різні формати даних
@@ -285,6 +320,22 @@ func (c *Client) SendTeleportEntity(eid int32, pos [3]float64, rot [2]int8, onGr
 	)
 }
 
+// SendSetEntityData відправляє метадані сутності: позу, прапорці, ім'я і т.д.
+func (c *Client) SendSetEntityData(eid int32, data entity.MetadataSet) {
+	c.SendPacket(packetid.ClientboundSetEntityData, pk.VarInt(eid), data)
+}
+
+// SendAnimate показує анімацію сутності, наприклад помах рукою
+func (c *Client) SendAnimate(eid int32, anim world.Animation) {
+	c.SendPacket(packetid.ClientboundAnimate, pk.VarInt(eid), pk.UnsignedByte(anim))
+}
+
+// SendEntityEvent показує подію сутності, наприклад смерть
+// ID сутності тут передається як Int, а не VarInt
+func (c *Client) SendEntityEvent(eid int32, event world.EntityEvent) {
+	c.SendPacket(packetid.ClientboundEntityEvent, pk.Int(eid), pk.Byte(event))
+}
+
 // Лічильник для ID телепортацій
 // Atomic щоб безпечно використовувати з різних потоків
 var teleportCounter atomic.Int32
@@ -384,11 +435,63 @@ func (c *Client) SendSetChunkCacheCenter(chunkPos [2]int32) {
 	)
 }
 
+// SendSetTime синхронізує вік світу і час доби
+// Від'ємний час доби означає, що клієнт не повинен рухати сонце сам
+func (c *Client) SendSetTime(worldAge, dayTime int64) {
+	c.SendPacket(
+		packetid.ClientboundSetTime,
+		pk.Long(worldAge),
+		pk.Long(dayTime),
+	)
+}
+
+// SendGameEvent відправляє подію гри: початок і кінець дощу, зміну сили дощу і т.д.
+func (c *Client) SendGameEvent(event byte, value float32) {
+	c.SendPacket(
+		packetid.ClientboundGameEvent,
+		pk.UnsignedByte(event),
+		pk.Float(value),
+	)
+}
+
+// SendBlockUpdate повідомляє, що блок в точці pos змінився
+func (c *Client) SendBlockUpdate(pos [3]int32, state block.StateID) {
+	c.SendPacket(
+		packetid.ClientboundBlockUpdate,
+		pk.Position{X: int(pos[0]), Y: int(pos[1]), Z: int(pos[2])},
+		pk.VarInt(state),
+	)
+}
+
+// SendSectionBlocksUpdate повідомляє про кілька змінених блоків однієї секції
+// Кожен блок пакується у VarLong: стан << 12 | x << 8 | z << 4 | y в межах секції
+func (c *Client) SendSectionBlocksUpdate(section [3]int32, changes []world.BlockChange) {
+	pos := int64(section[0]&0x3FFFFF)<<42 | int64(section[2]&0x3FFFFF)<<20 | int64(section[1]&0xFFFFF)
+	blocks := make([]pk.VarLong, len(changes))
+	for i, ch := range changes {
+		local := int64(ch.Pos[0]&15)<<8 | int64(ch.Pos[2]&15)<<4 | int64(ch.Pos[1]&15)
+		blocks[i] = pk.VarLong(int64(ch.State)<<12 | local)
+	}
+	c.SendPacket(
+		packetid.ClientboundSectionBlocksUpdate,
+		pk.Long(pos),
+		pk.Boolean(false), // світло перераховує клієнт
+		pk.Array(blocks),
+	)
+}
+
+// SendBlockChangedAck підтверджує, що сервер обробив дії з блоками до sequence
+// Після цього клієнт перестає вгадувати ці блоки і показує те, що прислав сервер
+func (c *Client) SendBlockChangedAck(sequence int32) {
+	c.SendPacket(packetid.ClientboundBlockChangedAck, pk.VarInt(sequence))
+}
+
 func (c *Client) ViewChunkLoad(pos level.ChunkPos, chunk *level.Chunk) {
 	c.SendLevelChunkWithLight(pos, chunk)
 }
 func (c *Client) ViewChunkUnload(pos level.ChunkPos)   { c.SendForgetLevelChunk(pos) }
 func (c *Client) ViewAddPlayer(p *world.Player)        { c.SendAddPlayer(p) }
+func (c *Client) ViewAddEntity(e *world.GenericEntity) { c.SendAddEntity(e) }
 func (c *Client) ViewRemoveEntities(entityIDs []int32) { c.SendRemoveEntities(entityIDs) }
 func (c *Client) ViewMoveEntityPos(id int32, delta [3]int16, onGround bool) {
 	c.SendMoveEntitiesPos(id, delta, onGround)
@@ -409,3 +512,27 @@ func (c *Client) ViewRotateHead(id int32, yaw int8) {
 func (c *Client) ViewTeleportEntity(id int32, pos [3]float64, rot [2]int8, onGround bool) {
 	c.SendTeleportEntity(id, pos, rot, onGround)
 }
+
+func (c *Client) ViewEntityData(id int32, data entity.MetadataSet) {
+	c.SendSetEntityData(id, data)
+}
+
+func (c *Client) ViewAnimate(id int32, anim world.Animation) {
+	c.SendAnimate(id, anim)
+}
+
+func (c *Client) ViewEntityEvent(id int32, event world.EntityEvent) {
+	c.SendEntityEvent(id, event)
+}
+
+func (c *Client) ViewBlockUpdate(pos [3]int32, state block.StateID) {
+	c.SendBlockUpdate(pos, state)
+}
+
+func (c *Client) ViewSectionBlocksUpdate(section [3]int32, changes []world.BlockChange) {
+	c.SendSectionBlocksUpdate(section, changes)
+}
+
+func (c *Client) ViewLightUpdate(pos level.ChunkPos, chunk *level.Chunk, sections uint32) {
+	c.SendLightUpdate(pos, chunk, sections)
+}
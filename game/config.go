@@ -25,6 +25,9 @@ import (
 
 	// rate використовуємо для обмеження навантаження
 	"golang.org/x/time/rate"
+
+	// world - налаштування світу
+	"FlowyCore/world"
 )
 
 // Config - головна структура з налаштуваннями сервера
@@ -60,8 +63,30 @@ type Config struct {
 	// Безпечний профіль = підписані повідомлення в чаті
 	EnforceSecureProfile bool `toml:"enforce-secure-profile"`
 
+	// Як часто автоматично зберігати світ і гравців на диск
+	// Наприклад "5m" = кожні 5 хвилин, "0s" = вимкнути автозбереження
+	AutosaveInterval duration `toml:"autosave-interval"`
+
+	// Скільки тіків на секунду виконує світ, ванільне значення - 20
+	TPS int `toml:"tps"`
+
+	// На скільки тіків світ може відстати від розкладу, перш ніж пропускати тіки
+	// Менше відставання наздоганяється тіками підряд
+	MaxCatchUpTicks int `toml:"max-catch-up-ticks"`
+
+	// Скільки груп гравців, які не бачать одна одну, тікати паралельно
+	// 0 або 1 = вимкнено
+	RegionThreads int `toml:"region-threads"`
+
+	// Кеш відкритих файлів регіонів (.mca)
+	RegionCache RegionCache `toml:"region-cache"`
+
+	// Генератор нових чанків
+	Generator Generator `toml:"generator"`
+
 	// Обмежувачі навантаження:
-	// ChunkLoadingLimiter - скільки чанків можна завантажити за раз
+	// ChunkLoadingLimiter - бюджет фонового завантаження чанків:
+	// N - скільки чанків вантажиться одночасно, Every - як часто можна брати новий
 	ChunkLoadingLimiter Limiter `toml:"chunk-loading-limiter"`
 	// PlayerChunkLoadingLimiter - скільки чанків може завантажити один гравець
 	PlayerChunkLoadingLimiter Limiter `toml:"player-chunk-loading-limiter"`
@@ -85,6 +110,49 @@ func (l *Limiter) Limiter() *rate.Limiter {
 	return rate.NewLimiter(rate.Every(l.Every.Duration), l.N)
 }
 
+// RegionCache - налаштування кешу файлів регіонів
+// Один регіон - це 32x32 чанки, тому навіть 32 відкритих регіони покривають величезну площу
+type RegionCache struct {
+	// Скільки регіонів тримати відкритими одночасно
+	MaxOpen int `toml:"max-open"`
+
+	// Через скільки часу без звернень закривати регіон
+	// Наприклад "1m" = через хвилину, "0s" = ніколи
+	IdleTimeout duration `toml:"idle-timeout"`
+}
+
+// Config перетворює налаштування кешу в формат пакету world
+func (r *RegionCache) Config() world.RegionCacheConfig {
+	return world.RegionCacheConfig{
+		MaxOpen:     r.MaxOpen,
+		IdleTimeout: r.IdleTimeout.Duration,
+	}
+}
+
+// Generator - налаштування генератора світу
+type Generator struct {
+	// Назва генератора: "void", "flat", "stone" або "noise"
+	Type string `toml:"type"`
+
+	// Далі налаштування тільки для "flat":
+	// Шари знизу вгору, наприклад "bedrock,2*dirt,grass_block"
+	Layers string `toml:"layers"`
+	// Біом всього світу, наприклад "plains"
+	Biome string `toml:"biome"`
+	// Набори структур, наприклад ["villages", "strongholds"]
+	Structures []string `toml:"structures"`
+}
+
+// Generator створює генератор світу за його налаштуваннями
+func (g *Generator) Generator() (world.Generator, error) {
+	return world.NewGenerator(world.GeneratorConfig{
+		Type:       g.Type,
+		Layers:     g.Layers,
+		Biome:      g.Biome,
+		Structures: g.Structures,
+	})
+}
+
 // duration - обгортка навколо time.Duration
 // Потрібна щоб читати тривалість з конфіг файлу
 type duration struct {
@@ -17,7 +17,6 @@
 package game
 
 import (
-	"compress/gzip"
 	"context"
 	"errors"
 	"os"
@@ -33,7 +32,6 @@ import (
 	"github.com/Tnze/go-mc/data/packetid"
 	"github.com/Tnze/go-mc/net"
 	pk "github.com/Tnze/go-mc/net/packet"
-	"github.com/Tnze/go-mc/save"
 	"github.com/Tnze/go-mc/server"
 	"github.com/Tnze/go-mc/yggdrasil/user"
 )
@@ -44,11 +42,14 @@ type Game struct {
 	config     Config
 	serverInfo *server.PingInfo
 
-	playerProvider world.PlayerProvider
+	playerProvider *world.PlayerProvider
 	overworld      *world.World
 
 	globalChat globalChat
 	*playerList
+
+	autosaveStop chan struct{} // закривається в Close, щоб зупинити автозбереження
+	autosaveDone chan struct{} // закривається, коли автозбереження зупинилось
 }
 
 func NewGame(log *zap.Logger, config Config, pingList *server.PlayerList, serverInfo *server.PingInfo) *Game {
@@ -67,7 +68,7 @@ func NewGame(log *zap.Logger, config Config, pingList *server.PlayerList, server
 	})
 	go keepAlive.Run(context.TODO())
 
-	return &Game{
+	g := &Game{
 		log: log.Named("game"),
 
 		config:     config,
@@ -82,30 +83,85 @@ func NewGame(log *zap.Logger, config Config, pingList *server.PlayerList, server
 			chatTypeCodec: &world.NetworkCodec.ChatType,
 		},
 		playerList: &pl,
+
+		autosaveStop: make(chan struct{}),
+		autosaveDone: make(chan struct{}),
+	}
+	go g.autosave(config.AutosaveInterval.Duration)
+	return g
+}
+
+// autosave періодично зберігає світ і гравців на диск
+// Щоб при падінні сервера гравці втратили не більше ніж один інтервал прогресу
+// Зупиняється через autosaveStop, щоб не зберігати світ одночасно з Close або після нього
+func (g *Game) autosave(interval time.Duration) {
+	defer close(g.autosaveDone)
+	if interval <= 0 {
+		g.log.Info("Autosave disabled")
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.autosaveStop:
+			return
+		case <-ticker.C:
+			g.log.Debug("Autosave")
+			g.save()
+		}
+	}
+}
+
+// save зберігає на диск гравців, чанки та level.dat
+func (g *Game) save() {
+	if err := g.overworld.SavePlayers(g.playerProvider); err != nil {
+		g.log.Error("Save players error", zap.Error(err))
+	}
+	g.overworld.SaveChunks()
+	if err := g.overworld.SaveLevel(); err != nil {
+		g.log.Error("Save level data error", zap.Error(err))
 	}
 }
 
+// Close зберігає весь стан гри перед зупинкою сервера
+func (g *Game) Close() {
+	// Чекаємо, поки автозбереження, яке вже почалось, закінчиться
+	close(g.autosaveStop)
+	<-g.autosaveDone
+
+	g.log.Info("Saving world before shutdown")
+	g.save()
+	if err := g.overworld.Close(); err != nil {
+		g.log.Error("Close overworld error", zap.Error(err))
+	}
+}
+
+// LogTickReport пише в лог статистику тіків світу
+// Допомагає знайти причину лагів на живому сервері без pprof
+func (g *Game) LogTickReport() {
+	stats := g.overworld.TickStats()
+	g.log.Info("Tick stats",
+		zap.Float64("tps", stats.TPS),
+		zap.Float64("mspt", stats.MSPT),
+		zap.Uint64("skipped", stats.Skipped))
+	g.overworld.LogTickReport()
+}
+
 // Йоу, чат! Зараз розберемо як створюється світ в майнкрафті!
 // createWorld створює новий світ або завантажує існуючий
 func createWorld(logger *zap.Logger, path string, config *Config) (*world.World, error) {
 	// Відкриваємо файл level.dat - це головний файл світу
 	// Тут зберігається вся базова інформація - спавн, сід, час, погода
-	f, err := os.Open(filepath.Join(path, "level.dat"))
+	// Світ тримає його в пам'яті і записує назад при збереженні
+	lv, err := world.LoadLevel(filepath.Join(path, "level.dat"))
 	// Якщо файл не знайдено - повертаємо помилку
 	if err != nil {
 		return nil, err
 	}
 
-	// level.dat зжатий через gzip для економії місця
-	// Створюємо читач який розпакує дані
-	r, err := gzip.NewReader(f)
-	if err != nil {
-		return nil, err
-	}
-
-	// Читаємо NBT дані з файлу
-	// NBT - це формат в якому майн зберігає всі дані
-	lv, err := save.ReadLevel(r)
+	// Генератор створює чанки, яких ще немає на диску
+	gen, err := config.Generator.Generator()
 	if err != nil {
 		return nil, err
 	}
@@ -115,16 +171,25 @@ func createWorld(logger *zap.Logger, path string, config *Config) (*world.World,
 		// Додаємо до логера префікс "overworld"
 		logger.Named("overworld"),
 		// Створюємо провайдер який буде читати чанки з папки region
-		// ChunkLoadingLimiter обмежує скільки чанків можна загрузити одночасно
-		world.NewProvider(filepath.Join(path, "region"), config.ChunkLoadingLimiter.Limiter()),
+		// RegionCache задає скільки файлів регіонів тримати відкритими
+		world.NewProvider(filepath.Join(path, "region"), config.RegionCache.Config()),
+		// Стан рівня: спавн, час, погода, правила гри
+		lv,
 		// Налаштування світу:
 		world.Config{
 			// На яку відстань гравці бачать світ (в чанках)
 			ViewDistance: config.ViewDistance,
-			// Кут повороту на спавні
-			SpawnAngle: lv.Data.SpawnAngle,
-			// Координати точки спавну
-			SpawnPosition: [3]int32{lv.Data.SpawnX, lv.Data.SpawnY, lv.Data.SpawnZ},
+			// ChunkLoadingLimiter - бюджет фонового завантаження чанків:
+			// N воркерів вантажать чанки паралельно, не частіше ніж раз на Every
+			ChunkLoadWorkers: config.ChunkLoadingLimiter.N,
+			ChunkLoadLimiter: config.ChunkLoadingLimiter.Limiter(),
+			// Генератор нових чанків з таблиці [generator]
+			Generator: gen,
+			// Швидкість тіків і скільки відставання наздоганяти
+			TPS:             config.TPS,
+			MaxCatchUpTicks: config.MaxCatchUpTicks,
+			// Паралельні тіки для гравців, які далеко один від одного
+			RegionThreads: config.RegionThreads,
 		},
 	)
 	return overworld, nil
@@ -209,6 +274,15 @@ func (g *Game) AcceptPlayer(name string, id uuid.UUID, profilePubKey *user.Publi
 	// Коли вийде - видалимо зі списку
 	defer g.playerList.removePlayer(c)
 
+	// Коли гравець вийде - збережемо його дані на диск
+	// defer виконуються в зворотному порядку, тому збереження відбудеться
+	// вже після RemovePlayer, коли тік-горутина більше не змінює гравця
+	defer func() {
+		if err := g.playerProvider.SavePlayer(p); err != nil {
+			logger.Error("Save player data error", zap.Error(err))
+		}
+	}()
+
 	// Телепортуємо гравця на його позицію
 	c.SendPlayerPosition(p.Position, p.Rotation)
 	// Додаємо гравця в світ (це почне відправку чанків)
@@ -10,10 +10,15 @@ import (
 	"FlowyCore/game"
 	// flag - це пакет для роботи з командним рядком, будемо використовувати для налаштувань
 	"flag"
+	// os і signal потрібні щоб зловити Ctrl+C і коректно зупинити сервер
+	"os"
+	"os/signal"
 	// debug дозволяє отримати інформацію про збірку програми
 	"runtime/debug"
 	// strings потрібен для роботи з текстом, будемо використовувати для форматування помилок
 	"strings"
+	// syscall містить SIGTERM
+	"syscall"
 
 	// toml - крутий формат для конфігів, як JSON але читабельніший
 	"github.com/BurntSushi/toml"
@@ -96,6 +101,9 @@ func main() {
 		return
 	}
 
+	// Створюємо ігрове ядро окремо, щоб при зупинці встигнути зберегти світ
+	g := game.NewGame(logger, config, playerList, serverInfo)
+
 	// Створюємо сам сервер - це головний об'єкт який все контролює
 	s := server.Server{
 		// Налаштовуємо логер для серверу
@@ -118,18 +126,41 @@ func main() {
 			LoginChecker: playerList,
 		},
 		// GamePlay - наше ігрове ядро, вся логіка гри тут
-		GamePlay: game.NewGame(logger, config, playerList, serverInfo),
+		GamePlay: g,
 	}
 
 	// Запускаємо сервер на вказаному адресі
 	// За замовчуванням це 0.0.0.0:25565 - стандартний порт майнкрафту
 	logger.Info("Start listening", zap.String("address", config.ListenAddress))
-	// Починаємо слухати підключення
-	err = s.Listen(config.ListenAddress)
-	// Якщо сталася помилка - пишемо в лог
-	if err != nil {
-		logger.Error("Server listening error", zap.Error(err))
+	// Починаємо слухати підключення в окремій горутині
+	// Listen ніколи не повертається сам, тільки з помилкою
+	listenErr := make(chan error, 1)
+	go func() { listenErr <- s.Listen(config.ListenAddress) }()
+
+	// Чекаємо Ctrl+C (SIGINT) або SIGTERM від systemd/docker
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	// Сигнал звіту (SIGUSR1 на Linux) пише в лог статистику тіків
+	report := make(chan os.Signal, 1)
+	notifyReport(report)
+
+Wait:
+	for {
+		select {
+		case err = <-listenErr:
+			// Якщо сталася помилка - пишемо в лог
+			logger.Error("Server listening error", zap.Error(err))
+			break Wait
+		case sig := <-stop:
+			logger.Info("Received stop signal", zap.Stringer("signal", sig))
+			break Wait
+		case <-report:
+			g.LogTickReport()
+		}
 	}
+	// Зберігаємо світ, гравців і level.dat перед виходом
+	g.Close()
 }
 
 // printBuildInfo виводить інформацію про збірку
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// notifyReport нічого не робить: на цій системі немає SIGUSR1
+func notifyReport(c chan<- os.Signal) {}
@@ -0,0 +1,15 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyReport підписується на сигнал звіту про тіки
+// Використання: kill -USR1 <pid>
+func notifyReport(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGUSR1)
+}
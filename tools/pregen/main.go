@@ -0,0 +1,270 @@
+// Йоу, чат! Зараз розберемо як заздалегідь згенерувати світ!
+// Генерація чанків - найважча робота сервера. Коли перші гравці
+// розбігаються в різні боки, сервер генерує сотні чанків одночасно і лагає.
+// Ця утиліта генерує квадрат чанків навколо центру ще до запуску сервера
+// і записує їх в .mca файли тим самим кодом, що й ChunkProvider.
+//
+// Використання:
+//
+//	go run ./tools/pregen -world world -center 0,0 -radius 64
+//
+// Якщо перервати генерацію (Ctrl+C), все згенероване буде збережено.
+// Повторний запуск пропустить чанки, які вже є на диску, і продовжить з того ж місця.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"FlowyCore/game"
+	"FlowyCore/world"
+)
+
+// options - параметри генерації з командного рядка
+type options struct {
+	worldDir string          // папка світу з level.dat
+	center   [2]int32        // центр квадрату в координатах чанків
+	radius   int32           // радіус в чанках
+	workers  int             // скільки чанків генерувати паралельно
+	gen      world.Generator // генератор з config.toml
+	cache    world.RegionCacheConfig
+	progress func(s stats) // викликається раз на секунду
+}
+
+// stats - лічильники прогресу генерації
+type stats struct {
+	total     int // всього чанків в квадраті
+	done      int // оброблено (згенеровано + пропущено)
+	generated int // згенеровано в цьому запуску
+	skipped   int // вже були на диску
+}
+
+func main() {
+	worldDir := flag.String("world", "world", "папка світу з level.dat")
+	center := flag.String("center", "0,0", "центр в координатах чанків, x,z")
+	radius := flag.Int("radius", 32, "радіус в чанках")
+	workers := flag.Int("workers", runtime.NumCPU(), "скільки чанків генерувати паралельно")
+	configPath := flag.String("config", "config.toml", "конфіг сервера з таблицею [generator]")
+	flag.Parse()
+
+	opts, err := buildOptions(*worldDir, *center, *radius, *workers, *configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "pregen:", err)
+		os.Exit(2)
+	}
+
+	// Ctrl+C зупиняє генерацію, але вже згенеровані чанки зберігаються
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	start := time.Now()
+	opts.progress = func(s stats) {
+		elapsed := time.Since(start).Seconds()
+		speed := float64(s.generated) / max(elapsed, 0.001)
+		eta := "?"
+		if speed > 0 {
+			eta = time.Duration(float64(s.total-s.done) / speed * float64(time.Second)).Round(time.Second).String()
+		}
+		fmt.Fprintf(os.Stderr, "\r%d/%d (%.1f%%) згенеровано %d, пропущено %d, %.0f чанків/с, залишилось %s   ",
+			s.done, s.total, float64(s.done)*100/float64(s.total), s.generated, s.skipped, speed, eta)
+	}
+
+	s, err := pregen(ctx, opts)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "pregen:", err)
+		os.Exit(1)
+	}
+	if s.done < s.total {
+		fmt.Fprintf(os.Stderr, "Перервано: %d з %d чанків готово, запустіть ще раз щоб продовжити\n", s.done, s.total)
+		os.Exit(130)
+	}
+	fmt.Fprintf(os.Stderr, "Готово: згенеровано %d, пропущено %d за %s\n",
+		s.generated, s.skipped, time.Since(start).Round(time.Second))
+}
+
+// buildOptions перевіряє аргументи і читає налаштування генератора з конфігу
+func buildOptions(worldDir, center string, radius, workers int, configPath string) (options, error) {
+	var opts options
+	xs, zs, ok := strings.Cut(center, ",")
+	x, errX := strconv.ParseInt(strings.TrimSpace(xs), 10, 32)
+	z, errZ := strconv.ParseInt(strings.TrimSpace(zs), 10, 32)
+	if !ok || errX != nil || errZ != nil {
+		return opts, fmt.Errorf("invalid center %q, want x,z", center)
+	}
+	if radius < 0 {
+		return opts, errors.New("radius must not be negative")
+	}
+
+	// Читаємо з конфігу тільки те, що стосується генерації
+	var config struct {
+		Generator   game.Generator   `toml:"generator"`
+		RegionCache game.RegionCache `toml:"region-cache"`
+	}
+	if _, err := toml.DecodeFile(configPath, &config); err != nil {
+		return opts, fmt.Errorf("read config fail: %w", err)
+	}
+	gen, err := config.Generator.Generator()
+	if err != nil {
+		return opts, err
+	}
+
+	return options{
+		worldDir: worldDir,
+		center:   [2]int32{int32(x), int32(z)},
+		radius:   int32(radius),
+		workers:  max(workers, 1),
+		gen:      gen,
+		cache:    config.RegionCache.Config(),
+	}, nil
+}
+
+// chunkPositions повертає всі чанки квадрату, згруповані по регіонах
+// Так кожен регіон дописується до кінця і закривається,
+// а не тримається відкритим поки генерується весь світ
+func chunkPositions(center [2]int32, radius int32) [][2]int32 {
+	positions := make([][2]int32, 0, (2*radius+1)*(2*radius+1))
+	for z := center[1] - radius; z <= center[1]+radius; z++ {
+		for x := center[0] - radius; x <= center[0]+radius; x++ {
+			positions = append(positions, [2]int32{x, z})
+		}
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		ri, rj := regionOf(positions[i]), regionOf(positions[j])
+		if ri[1] != rj[1] {
+			return ri[1] < rj[1]
+		}
+		return ri[0] < rj[0]
+	})
+	return positions
+}
+
+// regionOf повертає координати регіону, в якому лежить чанк
+func regionOf(pos [2]int32) [2]int32 {
+	return [2]int32{pos[0] >> 5, pos[1] >> 5}
+}
+
+// pregen генерує всі чанки квадрату, яких ще немає на диску
+// Повертає статистику навіть якщо генерацію перервали через ctx
+func pregen(ctx context.Context, opts options) (stats, error) {
+	lv, err := world.LoadLevel(filepath.Join(opts.worldDir, "level.dat"))
+	if err != nil {
+		return stats{}, err
+	}
+	seed := lv.Seed()
+	provider := world.NewProvider(filepath.Join(opts.worldDir, "region"), opts.cache)
+
+	positions := chunkPositions(opts.center, opts.radius)
+	var done, generated, skipped atomic.Int64
+	snapshot := func() stats {
+		return stats{
+			total:     len(positions),
+			done:      int(done.Load()),
+			generated: int(generated.Load()),
+			skipped:   int(skipped.Load()),
+		}
+	}
+
+	// Воркери беруть позиції з каналу, генерують і записують чанки
+	jobs := make(chan [2]int32)
+	errs := make(chan error, opts.workers)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pos := range jobs {
+				exist, err := provider.HasChunk(pos)
+				if err != nil {
+					errs <- fmt.Errorf("chunk %v: %w", pos, err)
+					return
+				}
+				if exist {
+					skipped.Add(1)
+				} else {
+					if err := provider.PutChunk(pos, opts.gen.Generate(pos, seed)); err != nil {
+						errs <- fmt.Errorf("chunk %v: %w", pos, err)
+						return
+					}
+					generated.Add(1)
+				}
+				done.Add(1)
+			}
+		}()
+	}
+
+	// Раз на секунду показуємо прогрес і скидаємо регіони на диск,
+	// щоб після падіння не довелося генерувати все заново
+	tickerDone := make(chan struct{})
+	var tickerWG sync.WaitGroup
+	tickerWG.Add(1)
+	go func() {
+		defer tickerWG.Done()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-tickerDone:
+				return
+			case <-ticker.C:
+				if opts.progress != nil {
+					opts.progress(snapshot())
+				}
+				if err := provider.Flush(); err != nil {
+					select {
+					case errs <- err:
+					default: // помилка вже є, зупинка і так почнеться
+					}
+				}
+			}
+		}
+	}()
+
+	var runErr error
+Dispatch:
+	for _, pos := range positions {
+		if ctx.Err() != nil {
+			break // select нижче обирає випадково, тому перевіряємо зупинку окремо
+		}
+		select {
+		case jobs <- pos:
+		case <-ctx.Done():
+			break Dispatch
+		case runErr = <-errs:
+			break Dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(tickerDone)
+	tickerWG.Wait()
+
+	// Збираємо помилки воркерів, які не встигли прочитати
+	if runErr == nil {
+		select {
+		case runErr = <-errs:
+		default:
+		}
+	}
+	if opts.progress != nil {
+		opts.progress(snapshot())
+	}
+	// Close зберігає всі змінені регіони, навіть якщо генерацію перервали
+	return snapshot(), errors.Join(runErr, provider.Close())
+}
@@ -0,0 +1,86 @@
+// Йоу, чат! Перевіряємо що pregen генерує квадрат чанків
+// і що повторний запуск продовжує роботу, а не починає заново.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"FlowyCore/world"
+)
+
+// newTestWorld створює папку світу з level.dat з репозиторію
+func newTestWorld(t *testing.T) string {
+	dir := t.TempDir()
+	data, err := os.ReadFile(filepath.Join("..", "..", "world", "level.dat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "level.dat"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// TestPregen_resume генерує світ, а потім запускає генерацію ще раз
+func TestPregen_resume(t *testing.T) {
+	dir := newTestWorld(t)
+	gen, err := world.NewGenerator(world.GeneratorConfig{Type: "flat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := options{
+		worldDir: dir,
+		center:   [2]int32{31, 0}, // квадрат перетинає межу регіонів
+		radius:   2,
+		workers:  3,
+		gen:      gen,
+		cache:    world.RegionCacheConfig{MaxOpen: 1},
+	}
+
+	s, err := pregen(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.total != 25 || s.generated != 25 || s.skipped != 0 {
+		t.Fatalf("first run: %+v", s)
+	}
+	for _, name := range []string{"r.0.0.mca", "r.1.0.mca", "r.0.-1.mca", "r.1.-1.mca"} {
+		if _, err := os.Stat(filepath.Join(dir, "region", name)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Другий запуск з більшим радіусом догенеровує тільки нові чанки
+	opts.radius = 3
+	s, err = pregen(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.total != 49 || s.generated != 24 || s.skipped != 25 {
+		t.Fatalf("second run: %+v", s)
+	}
+}
+
+// TestPregen_cancel перевіряє що перервана генерація зберігає готові чанки
+func TestPregen_cancel(t *testing.T) {
+	dir := newTestWorld(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s, err := pregen(ctx, options{
+		worldDir: dir,
+		radius:   4,
+		workers:  1,
+		gen:      world.VoidGenerator{},
+		cache:    world.RegionCacheConfig{MaxOpen: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.done == s.total {
+		t.Errorf("cancelled run has finished everything: %+v", s)
+	}
+}
@@ -0,0 +1,170 @@
+// Йоу, чат! Коли .mca файл ламається, сервер пише в лог "parse chunk data fail",
+// а гравці бачать дірки в світі. Ця утиліта допомагає розібратись, що сталося.
+//
+// Використання:
+//
+//	go run ./tools/regiontool list world/region/r.0.0.mca
+//	go run ./tools/regiontool dump world/region/r.0.0.mca 3 7
+//	go run ./tools/regiontool validate world/region/*.mca
+//	go run ./tools/regiontool repair world/region/r.0.0.mca
+//
+// dump приймає локальні координати чанку в регіоні (0..31).
+// repair зберігає оригінальний файл поруч з розширенням .bak.
+// Запускайте repair тільки на зупиненому сервері!
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Tnze/go-mc/nbt"
+)
+
+const usage = `usage:
+  regiontool list <r.X.Z.mca>
+  regiontool dump <r.X.Z.mca> <x> <z>
+  regiontool validate <r.X.Z.mca>...
+  regiontool repair <r.X.Z.mca>...`
+
+// errInvalid означає, що validate знайшов проблеми
+var errInvalid = errors.New("region files have problems")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "regiontool:", err)
+		os.Exit(1)
+	}
+}
+
+// run виконує підкоманду і пише результат в out
+func run(args []string, out io.Writer) error {
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	switch cmd, files := args[0], args[1:]; cmd {
+	case "list":
+		if len(files) != 1 {
+			return errors.New(usage)
+		}
+		return list(files[0], out)
+	case "dump":
+		if len(files) != 3 {
+			return errors.New(usage)
+		}
+		x, errX := strconv.Atoi(files[1])
+		z, errZ := strconv.Atoi(files[2])
+		if errX != nil || errZ != nil || x < 0 || x > 31 || z < 0 || z > 31 {
+			return fmt.Errorf("invalid chunk position %s,%s, want 0..31", files[1], files[2])
+		}
+		return dump(files[0], x, z, out)
+	case "validate":
+		return validate(files, out)
+	case "repair":
+		for _, f := range files {
+			actions, err := repairRegion(f)
+			if err != nil {
+				return fmt.Errorf("%s: %w", f, err)
+			}
+			if len(actions) == 0 {
+				fmt.Fprintf(out, "%s: nothing to repair\n", f)
+				continue
+			}
+			for _, a := range actions {
+				fmt.Fprintf(out, "%s: %s\n", f, a)
+			}
+			fmt.Fprintf(out, "%s: repaired, original saved as %s.bak\n", f, f)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", cmd, usage)
+	}
+}
+
+// list виводить таблицю всіх чанків регіону
+func list(path string, out io.Writer) error {
+	r, err := readRegion(path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%-7s %-13s %6s %5s %8s %-12s %s\n", "slot", "chunk", "sector", "count", "length", "compression", "modified")
+	for _, e := range r.entries {
+		pos := "?"
+		if e.decoded {
+			pos = fmt.Sprintf("%d,%d", e.pos[0], e.pos[1])
+		}
+		modified := time.Unix(int64(e.timestamp), 0).UTC().Format(time.DateTime)
+		fmt.Fprintf(out, "%-7s %-13s %6d %5d %8d %-12s %s\n",
+			fmt.Sprintf("%d,%d", e.x, e.z), pos, e.sector, e.count, e.length, compressionName(e.compression), modified)
+	}
+	fmt.Fprintf(out, "%d chunks, %d bytes\n", len(r.entries), r.size)
+	return nil
+}
+
+// dump виводить NBT чанку як текст (SNBT)
+func dump(path string, x, z int, out io.Writer) error {
+	r, err := readRegion(path)
+	if err != nil {
+		return err
+	}
+	for _, e := range r.entries {
+		if e.x != x || e.z != z {
+			continue
+		}
+		if !e.decoded {
+			return fmt.Errorf("chunk %d,%d is corrupt: %v", x, z, e.problems)
+		}
+		payload := e.payload
+		if e.compression&externalBit != 0 {
+			payload, err = readExternal(r.path, r.expectedPos(x, z), e.compression)
+			if err != nil {
+				return err
+			}
+		}
+		// Розпаковуємо так само, як save.Chunk.Load, але зберігаємо сирий NBT
+		data, err := decompress(payload)
+		if err != nil {
+			return err
+		}
+		var raw nbt.RawMessage
+		if _, err := nbt.NewDecoder(data).Decode(&raw); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, raw.String())
+		return err
+	}
+	return fmt.Errorf("chunk %d,%d does not exist", x, z)
+}
+
+// validate перевіряє файли і виводить всі знайдені проблеми
+func validate(files []string, out io.Writer) error {
+	bad := 0
+	for _, f := range files {
+		r, err := readRegion(f)
+		if err != nil {
+			fmt.Fprintf(out, "%s: %v\n", f, err)
+			bad++
+			continue
+		}
+		problems := 0
+		for _, e := range r.entries {
+			for _, p := range e.problems {
+				fmt.Fprintf(out, "%s: chunk %d,%d: %s\n", f, e.x, e.z, p)
+				problems++
+			}
+		}
+		if problems > 0 {
+			bad++
+		} else {
+			fmt.Fprintf(out, "%s: ok, %d chunks\n", f, len(r.entries))
+		}
+	}
+	if bad > 0 {
+		return fmt.Errorf("%w: %d of %d", errInvalid, bad, len(files))
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Tnze/go-mc/nbt"
+	"github.com/Tnze/go-mc/save/region"
+)
+
+// testChunk записує NBT чанку вручну, як tools/create_chunk.go
+func testChunk(t *testing.T, x, z int32, compression byte) []byte {
+	t.Helper()
+	chunk := map[string]any{
+		"DataVersion": int32(3337), // chunkDataVersion з world/provider.go (1.19.4)
+		"xPos":        x,
+		"yPos":        int32(-4),
+		"zPos":        z,
+		"Status":      "full",
+		"LastUpdate":  int64(0),
+		"sections": []map[string]any{{
+			"Y": int8(-4),
+			"block_states": map[string]any{
+				"palette": []map[string]any{{"Name": "minecraft:bedrock"}},
+			},
+			"biomes": map[string]any{
+				"palette": []string{"minecraft:plains"},
+			},
+		}},
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(compression)
+	var w interface {
+		Write([]byte) (int, error)
+		Close() error
+	}
+	switch compression {
+	case 1:
+		w = gzip.NewWriter(&buf)
+	case 2:
+		w = zlib.NewWriter(&buf)
+	}
+	if err := nbt.NewEncoder(w).Encode(chunk, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// writeTestRegion створює r.1.-1.mca з чанками в слотах 0,0 / 1,0 / 2,0 / 0,1 / 5,5
+func writeTestRegion(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "r.1.-1.mca")
+	r, err := region.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range [][2]int{{0, 0}, {1, 0}, {2, 0}, {0, 1}} {
+		if err := r.WriteSector(s[0], s[1], testChunk(t, int32(32+s[0]), int32(-32+s[1]), 2)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// gzip теж має читатись
+	if err := r.WriteSector(5, 5, testChunk(t, 37, -27, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.PadToFullSector(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// sectorOf повертає зсув даних чанку в файлі
+func sectorOf(t *testing.T, data []byte, x, z int) int {
+	t.Helper()
+	return int(binary.BigEndian.Uint32(data[(z*32+x)*4:])>>8) * sectorSize
+}
+
+// corrupt ламає файл регіону функцією f
+func corrupt(t *testing.T, path string, f func(data []byte)) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f(data)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidate_clean(t *testing.T) {
+	path := writeTestRegion(t)
+	var out bytes.Buffer
+	if err := run([]string{"validate", path}, &out); err != nil {
+		t.Fatalf("validate: %v\n%s", err, &out)
+	}
+	if !strings.Contains(out.String(), "ok, 5 chunks") {
+		t.Errorf("unexpected output:\n%s", &out)
+	}
+
+	out.Reset()
+	if err := run([]string{"list", path}, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"0,1     32,-31", "5,5     37,-27", "gzip", "zlib", "5 chunks"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("list output has no %q:\n%s", want, &out)
+		}
+	}
+
+	out.Reset()
+	if err := run([]string{"dump", path, "1", "0"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"xPos:33", "zPos:-32", `Name:"minecraft:bedrock"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dump output has no %q:\n%s", want, &out)
+		}
+	}
+}
+
+// Кожен вид пошкодження має бути знайдений, а repair має викинути зламане
+// і перенести чанк, який лежить не в своєму слоті
+func TestValidateAndRepair(t *testing.T) {
+	path := writeTestRegion(t)
+	corrupt(t, path, func(data []byte) {
+		// 0,0: невідомий тип стиснення
+		data[sectorOf(t, data, 0, 0)+4] = 9
+		// 1,0: довжина більша за сектори
+		binary.BigEndian.PutUint32(data[sectorOf(t, data, 1, 0):], 5*sectorSize)
+		// 2,0: зсув вказує в заголовок
+		binary.BigEndian.PutUint32(data[2*4:], 1<<8|1)
+		// 5,5 -> 7,3: чанк лежить не в своєму слоті
+		binary.BigEndian.PutUint32(data[(3*32+7)*4:], binary.BigEndian.Uint32(data[(5*32+5)*4:]))
+		binary.BigEndian.PutUint32(data[(5*32+5)*4:], 0)
+	})
+
+	var out bytes.Buffer
+	err := run([]string{"validate", path}, &out)
+	if !errors.Is(err, errInvalid) {
+		t.Fatalf("validate must fail, got %v", err)
+	}
+	for _, want := range []string{
+		"chunk 0,0: unknown compression type 9",
+		"chunk 1,0: data length 20480 does not fit into 1 sectors",
+		"chunk 2,0: offset 1 points into the header",
+		"chunk 7,3: chunk says it is at 37,-27, but the slot is for 39,-29",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("validate output has no %q:\n%s", want, &out)
+		}
+	}
+	if strings.Contains(out.String(), "chunk 0,1") {
+		t.Errorf("healthy chunk reported:\n%s", &out)
+	}
+
+	out.Reset()
+	if err := run([]string{"repair", path}, &out); err != nil {
+		t.Fatalf("repair: %v\n%s", err, &out)
+	}
+	if !strings.Contains(out.String(), "move 7,3 to 5,5") {
+		t.Errorf("repair did not move chunk:\n%s", &out)
+	}
+	if _, err := os.Stat(path + ".bak"); err != nil {
+		t.Errorf("no backup: %v", err)
+	}
+
+	r, err := readRegion(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[[2]int][2]int32)
+	for _, e := range r.entries {
+		if len(e.problems) > 0 {
+			t.Errorf("chunk %d,%d still has problems: %v", e.x, e.z, e.problems)
+		}
+		got[[2]int{e.x, e.z}] = e.pos
+	}
+	want := map[[2]int][2]int32{{0, 1}: {32, -31}, {5, 5}: {37, -27}}
+	if len(got) != len(want) || got[[2]int{0, 1}] != want[[2]int{0, 1}] || got[[2]int{5, 5}] != want[[2]int{5, 5}] {
+		t.Errorf("repaired region has %v, want %v", got, want)
+	}
+
+	// Повторний repair нічого не робить
+	out.Reset()
+	if err := run([]string{"repair", path}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "nothing to repair") {
+		t.Errorf("second repair changed something:\n%s", &out)
+	}
+}
@@ -0,0 +1,219 @@
+// Йоу, чат! Тут ми розбираємо .mca файл байт за байтом!
+// go-mc вміє читати регіони, але на зіпсованому файлі він просто повертає помилку.
+// Нам же треба знати, що саме зламано, тому заголовок і сектори читаємо самі.
+//
+// Формат регіону:
+// - 4096 байт таблиця розташувань: для кожного з 32x32 чанків
+//   3 байти - номер першого сектору і 1 байт - кількість секторів
+// - 4096 байт таблиця часу останнього запису кожного чанку
+// - далі сектори по 4096 байт; дані чанку починаються з 4 байт довжини,
+//   1 байту типу стиснення (1 - gzip, 2 - zlib, 3 - без стиснення,
+//   +128 - дані лежать в окремому файлі c.X.Z.mcc) і самих стиснутих даних
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Tnze/go-mc/save"
+)
+
+const (
+	sectorSize  = 4096
+	headerSize  = 2 * sectorSize
+	externalBit = 128 // біт типу стиснення, який означає окремий .mcc файл
+)
+
+// entry - запис про один чанк в регіоні
+type entry struct {
+	x, z        int      // локальні координати в регіоні (0..31)
+	sector      int      // номер першого сектору
+	count       int      // кількість секторів
+	timestamp   uint32   // час останнього запису (unix)
+	length      int      // довжина даних з заголовку сектору
+	compression byte     // тип стиснення
+	payload     []byte   // тип стиснення + стиснуті дані, як їх повертає region.ReadSector
+	decoded     bool     // дані успішно розпаковані і розібрані як NBT
+	pos         [2]int32 // xPos і zPos з NBT чанку
+	problems    []string // що не так з цим записом
+}
+
+// regionFile - розібраний файл регіону
+type regionFile struct {
+	path    string
+	coords  [2]int // координати регіону з назви файлу
+	hasPos  bool   // чи вдалося розібрати координати з назви
+	size    int    // розмір файлу в байтах
+	entries []*entry
+}
+
+// regionCoords розбирає координати з назви файлу r.X.Z.mca
+func regionCoords(path string) ([2]int, bool) {
+	parts := strings.Split(filepath.Base(path), ".")
+	if len(parts) != 4 || parts[0] != "r" || parts[3] != "mca" {
+		return [2]int{}, false
+	}
+	x, errX := strconv.Atoi(parts[1])
+	z, errZ := strconv.Atoi(parts[2])
+	return [2]int{x, z}, errX == nil && errZ == nil
+}
+
+// expectedPos повертає абсолютні координати чанку, який має лежати в слоті x, z
+func (r *regionFile) expectedPos(x, z int) [2]int32 {
+	return [2]int32{int32(r.coords[0]*32 + x), int32(r.coords[1]*32 + z)}
+}
+
+// readRegion читає і перевіряє весь файл регіону
+// Помилку повертає тільки якщо файл неможливо прочитати,
+// всі проблеми з даними записуються в entry.problems
+func readRegion(path string) (*regionFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &regionFile{path: path, size: len(data)}
+	r.coords, r.hasPos = regionCoords(path)
+	if len(data) < headerSize {
+		return r, fmt.Errorf("file is %d bytes, shorter than the %d byte header", len(data), headerSize)
+	}
+
+	owners := make(map[int]*entry) // сектор -> перший чанк, який його займає
+	for i := 0; i < 32*32; i++ {
+		loc := binary.BigEndian.Uint32(data[i*4:])
+		if loc == 0 {
+			continue // чанку немає
+		}
+		e := &entry{
+			x:         i % 32,
+			z:         i / 32,
+			sector:    int(loc >> 8),
+			count:     int(loc & 0xFF),
+			timestamp: binary.BigEndian.Uint32(data[sectorSize+i*4:]),
+		}
+		r.entries = append(r.entries, e)
+		r.readEntry(e, data)
+
+		// Два чанки не можуть ділити один сектор
+		for s := e.sector; s < e.sector+e.count && s >= 2; s++ {
+			if other, ok := owners[s]; ok {
+				e.problems = append(e.problems, fmt.Sprintf("sector %d overlaps with chunk %d,%d", s, other.x, other.z))
+				break
+			}
+			owners[s] = e
+		}
+	}
+	return r, nil
+}
+
+// readEntry читає дані чанку з секторів і перевіряє їх
+func (r *regionFile) readEntry(e *entry, data []byte) {
+	problem := func(format string, args ...any) {
+		e.problems = append(e.problems, fmt.Sprintf(format, args...))
+	}
+	switch {
+	case e.sector < 2:
+		problem("offset %d points into the header", e.sector)
+		return
+	case e.count == 0:
+		problem("zero sector count")
+		return
+	}
+	start := e.sector * sectorSize
+	if start+5 > len(data) {
+		problem("sector %d is beyond the end of file", e.sector)
+		return
+	}
+	e.length = int(binary.BigEndian.Uint32(data[start:]))
+	switch {
+	case e.length == 0:
+		problem("zero data length")
+		return
+	case e.length > e.count*sectorSize-4:
+		problem("data length %d does not fit into %d sectors", e.length, e.count)
+		return
+	case start+4+e.length > len(data):
+		problem("data length %d runs past the end of file", e.length)
+		return
+	}
+	e.payload = data[start+4 : start+4+e.length]
+	e.compression = e.payload[0]
+
+	// Великі чанки лежать в окремому файлі, а в регіоні - тільки тип стиснення
+	payload := e.payload
+	if e.compression&externalBit != 0 {
+		var err error
+		payload, err = readExternal(r.path, r.expectedPos(e.x, e.z), e.compression)
+		if err != nil {
+			problem("external chunk: %v", err)
+			return
+		}
+	}
+	switch payload[0] {
+	case 1, 2, 3:
+	default:
+		problem("unknown compression type %d", payload[0])
+		return
+	}
+
+	var c save.Chunk
+	if err := c.Load(payload); err != nil {
+		problem("decode chunk: %v", err)
+		return
+	}
+	e.decoded = true
+	e.pos = [2]int32{c.XPos, c.ZPos}
+	if r.hasPos {
+		if want := r.expectedPos(e.x, e.z); e.pos != want {
+			problem("chunk says it is at %d,%d, but the slot is for %d,%d", e.pos[0], e.pos[1], want[0], want[1])
+		}
+	} else if int(e.pos[0])&31 != e.x || int(e.pos[1])&31 != e.z {
+		problem("chunk says it is at %d,%d, which does not match slot %d,%d", e.pos[0], e.pos[1], e.x, e.z)
+	}
+}
+
+// readExternal читає дані чанку з окремого файлу c.X.Z.mcc поруч з регіоном
+// Повертає їх в тому ж форматі, що й сектор: тип стиснення + стиснуті дані
+func readExternal(regionPath string, pos [2]int32, compression byte) ([]byte, error) {
+	mcc := filepath.Join(filepath.Dir(regionPath), fmt.Sprintf("c.%d.%d.mcc", pos[0], pos[1]))
+	ext, err := os.ReadFile(mcc)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{compression &^ externalBit}, ext...), nil
+}
+
+// decompress розпаковує дані чанку так само, як save.Chunk.Load
+func decompress(payload []byte) (io.Reader, error) {
+	data := bytes.NewReader(payload[1:])
+	switch payload[0] {
+	case 1:
+		return gzip.NewReader(data)
+	case 2:
+		return zlib.NewReader(data)
+	case 3:
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown compression type %d", payload[0])
+	}
+}
+
+// compressionName повертає назву типу стиснення для виводу
+func compressionName(c byte) string {
+	name := map[byte]string{1: "gzip", 2: "zlib", 3: "none"}[c&^externalBit]
+	if name == "" {
+		name = strconv.Itoa(int(c))
+	}
+	if c&externalBit != 0 {
+		name += "+external"
+	}
+	return name
+}
@@ -0,0 +1,101 @@
+// Йоу, чат! А тепер лагодимо зіпсований регіон!
+// Ідея проста: збираємо всі чанки, які вдалося прочитати, і записуємо їх
+// в новий чистий файл. Зламані чанки викидаємо - сервер згенерує їх заново.
+// Чанки, які лежать не в своєму слоті, переносимо туди, де вони мають бути.
+// Старий файл не видаляємо, а перейменовуємо в .bak, щоб нічого не втратити.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Tnze/go-mc/save/region"
+)
+
+// repairRegion переписує регіон, викидаючи і переносячи зіпсовані чанки
+// Повертає список виконаних дій; якщо лагодити нічого - список порожній і файл не змінюється
+// Час останнього запису чанків при цьому оновлюється на поточний
+func repairRegion(path string) ([]string, error) {
+	r, err := readRegion(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []string
+	changed := false
+	slots := make(map[[2]int]*entry) // слот -> чанк, який там опиниться
+	for _, e := range r.entries {
+		if len(e.problems) > 0 {
+			changed = true
+		}
+		if !e.decoded {
+			actions = append(actions, fmt.Sprintf("drop %d,%d: %s", e.x, e.z, e.problems[0]))
+			continue
+		}
+		if r.hasPos && (int(e.pos[0])>>5 != r.coords[0] || int(e.pos[1])>>5 != r.coords[1]) {
+			actions = append(actions, fmt.Sprintf("drop %d,%d: chunk %d,%d belongs to another region", e.x, e.z, e.pos[0], e.pos[1]))
+			continue
+		}
+		dest := [2]int{int(e.pos[0]) & 31, int(e.pos[1]) & 31}
+		if dest != [2]int{e.x, e.z} && e.compression&externalBit != 0 {
+			actions = append(actions, fmt.Sprintf("drop %d,%d: external chunk in a wrong slot", e.x, e.z))
+			continue
+		}
+		if prev, ok := slots[dest]; ok {
+			// Два чанки претендують на один слот: лишаємо той, що вже там лежить, або новіший
+			if prev.x == dest[0] && prev.z == dest[1] || prev.timestamp >= e.timestamp {
+				actions = append(actions, fmt.Sprintf("drop %d,%d: duplicate of chunk in slot %d,%d", e.x, e.z, dest[0], dest[1]))
+				continue
+			}
+			actions = append(actions, fmt.Sprintf("drop %d,%d: duplicate of chunk in slot %d,%d", prev.x, prev.z, dest[0], dest[1]))
+		}
+		slots[dest] = e
+	}
+	if !changed {
+		return nil, nil
+	}
+
+	dests := make([][2]int, 0, len(slots))
+	for dest, e := range slots {
+		if dest != [2]int{e.x, e.z} {
+			actions = append(actions, fmt.Sprintf("move %d,%d to %d,%d", e.x, e.z, dest[0], dest[1]))
+		}
+		dests = append(dests, dest)
+	}
+	sort.Slice(dests, func(i, j int) bool {
+		return dests[i][1]*32+dests[i][0] < dests[j][1]*32+dests[j][0]
+	})
+
+	// Пишемо новий регіон поруч і підміняємо ним старий
+	tmp := path + ".repair.tmp"
+	out, err := region.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	for _, dest := range dests {
+		if err := out.WriteSector(dest[0], dest[1], slots[dest].payload); err != nil {
+			_ = out.Close()
+			_ = os.Remove(tmp)
+			return nil, fmt.Errorf("write chunk %d,%d: %w", dest[0], dest[1], err)
+		}
+	}
+	if err := out.PadToFullSector(); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(path, path+".bak"); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
@@ -0,0 +1,67 @@
+// Йоу, чат! Зараз розберемо як інші гравці бачать, що ти махнув рукою!
+// Анімації - це разові події, а не стан, тому метадані тут не підходять.
+// Клієнт відправляє ServerboundSwing, ми кладемо анімацію в чергу Inputs,
+// а в тіку розсилаємо її всім, хто бачить гравця: ClientboundAnimate.
+// Події сутності (смерть, серця в тварин, тотем) йдуть пакетом ClientboundEntityEvent.
+// Гравець, який махнув рукою, свою анімацію вже бачить, тому йому нічого не шлемо.
+
+package world
+
+import "FlowyCore/world/internal/bvh"
+
+// Animation - анімація з ClientboundAnimate
+type Animation byte
+
+const (
+	AnimationSwingMainArm   Animation = 0 // махнути основною рукою
+	AnimationLeaveBed       Animation = 2 // встати з ліжка
+	AnimationSwingOffhand   Animation = 3 // махнути другою рукою
+	AnimationCriticalEffect Animation = 4 // частинки критичного удару
+	AnimationMagicCritical  Animation = 5 // частинки зачарованого удару
+)
+
+// EntityEvent - подія з ClientboundEntityEvent
+// Значення залежить від типу сутності, тут тільки найчастіші
+type EntityEvent byte
+
+const (
+	EntityEventDeath          EntityEvent = 3  // смерть живої сутності
+	EntityEventUseItemDone    EntityEvent = 9  // гравець доїв або допив
+	EntityEventLoveHearts     EntityEvent = 18 // серця над твариною
+	EntityEventShieldBlock    EntityEvent = 29 // щит заблокував удар
+	EntityEventShieldBreak    EntityEvent = 30 // щит зламався
+	EntityEventTotemOfUndying EntityEvent = 35 // спрацював тотем
+)
+
+// PlayAnimation показує анімацію сутності всім, хто її бачить
+// Викликати тільки з тік-горутини, наприклад із задачі планувальника
+func (w *World) PlayAnimation(e *Entity, anim Animation) {
+	w.broadcastToViewers(e, func(v EntityViewer) { v.ViewAnimate(e.EntityID, anim) })
+}
+
+// PlayEntityEvent показує подію сутності всім, хто її бачить
+// Викликати тільки з тік-горутини, наприклад із задачі планувальника
+func (w *World) PlayEntityEvent(e *Entity, event EntityEvent) {
+	w.broadcastToViewers(e, func(v EntityViewer) { v.ViewEntityEvent(e.EntityID, event) })
+}
+
+// broadcastToViewers викликає send для кожного глядача, в якого сутність вже є в EntitiesInView
+func (w *World) broadcastToViewers(e *Entity, send func(v EntityViewer)) {
+	w.playerViews.Find(bvh.TouchPoint[vec3d, aabb3d](vec3d(e.Position)),
+		func(n *playerViewNode) bool {
+			if _, ok := n.Value.EntitiesInView[e.EntityID]; ok {
+				send(n.Value.EntityViewer)
+			}
+			return true
+		},
+	)
+}
+
+// playAnimations розсилає анімації, які гравець зробив з минулого тіку
+// Викликати, коли p.Inputs заблоковані
+func (w *World) playAnimations(p *Player) {
+	for _, anim := range p.Inputs.Animations {
+		w.PlayAnimation(&p.Entity, anim)
+	}
+	p.Inputs.Animations = p.Inputs.Animations[:0]
+}
@@ -0,0 +1,36 @@
+package world
+
+import "testing"
+
+func TestPlayAnimations(t *testing.T) {
+	w := &World{players: make(map[Client]*Player)}
+	add := func(x float64) (*fakeClient, *Player) {
+		r := &fakeClient{}
+		p := &Player{
+			Entity:         Entity{EntityID: NewEntityID(), Position: [3]float64{x, 64, 0}},
+			ViewDistance:   2,
+			EntitiesInView: make(map[int32]*Entity),
+		}
+		p.view = w.playerViews.Insert(p.getView(), playerView{r, p})
+		w.players[r] = p
+		return r, p
+	}
+	_, swinger := add(0)
+	watcher, wp := add(10)
+	stranger, _ := add(12) // стоїть поруч, але ще не отримав гравця в EntitiesInView
+	wp.EntitiesInView[swinger.EntityID] = &swinger.Entity
+
+	swinger.Inputs.Animations = []Animation{AnimationSwingMainArm, AnimationSwingOffhand}
+	w.playAnimations(swinger)
+	w.PlayEntityEvent(&swinger.Entity, EntityEventTotemOfUndying)
+
+	if len(watcher.anims) != 2 || watcher.anims[1] != AnimationSwingOffhand || len(watcher.entityEvents) != 1 {
+		t.Errorf("watcher got %v %v", watcher.anims, watcher.entityEvents)
+	}
+	if len(stranger.anims) != 0 || len(stranger.entityEvents) != 0 {
+		t.Errorf("viewer without the entity got %v %v", stranger.anims, stranger.entityEvents)
+	}
+	if len(swinger.Inputs.Animations) != 0 {
+		t.Error("animations were not drained")
+	}
+}
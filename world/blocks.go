@@ -0,0 +1,275 @@
+// Йоу, чат! Зараз розберемо як сервер читає і змінює блоки в світі!
+// Блоки лежать в завантажених чанках: чанк -> секція 16x16x16 -> палітра.
+// GetBlock і SetBlock працюють з абсолютними координатами, тому ні логіка гри,
+// ні команди, ні плагіни не лізуть в LoadedChunk.Sections руками.
+//
+// Обидві функції можна викликати з будь-якої горутини: мапу чанків захищає
+// chunksLock, а сам чанк - його м'ютекс. Якщо чанк ще не завантажений,
+// він ставиться в чергу, а SetBlock застосується, коли чанк прийде з диска.
+// Такий чанк без гравців ще blockChunkKeepAlive перевірок не вивантажується.
+//
+// Гравцям зміни відправляються не одразу, а в кінці тіку:
+// всі змінені блоки однієї секції йдуть одним ClientboundSectionBlocksUpdate,
+// а якщо блок в секції один - звичайним ClientboundBlockUpdate.
+
+package world
+
+import (
+	"math"
+	"sync"
+
+	"github.com/Tnze/go-mc/level/block"
+)
+
+// blockChunkKeepAlive - скільки перевірок вивантаження (кожні 8 тіків) живе чанк
+// без спостерігачів після останнього GetBlock чи SetBlock, приблизно 6 секунд
+const blockChunkKeepAlive = 15
+
+// BlockChange - новий стан блоку для ClientboundSectionBlocksUpdate
+type BlockChange struct {
+	Pos   [3]int32 // абсолютні координати блоку
+	State block.StateID
+}
+
+// pendingBlock - зміна блоку в чанку, який ще не завантажений
+type pendingBlock struct {
+	x, y, z int
+	state   block.StateID
+}
+
+// blockUpdates - зміни блоків, які ще не дійшли до гравців
+// Змінюється з будь-якої горутини, тому має власний м'ютекс
+type blockUpdates struct {
+	sync.Mutex
+	sections map[[3]int32]map[[3]int32]struct{} // секція (x, y, z в секціях) -> змінені блоки
+	load     map[[2]int32][]pendingBlock        // чанки, які треба завантажити, і зміни для них
+}
+
+// GetBlock повертає блок в точці x, y, z
+// false - y за межами світу або чанк не завантажений. Тоді чанк ставиться
+// в чергу на завантаження, і через кілька тіків блок можна буде прочитати
+// Можна викликати з будь-якої горутини, в тому числі із задач планувальника
+func (w *World) GetBlock(x, y, z int) (block.StateID, bool) {
+	if y < chunkMinY || y >= chunkMinY+chunkHeight {
+		return 0, false
+	}
+	pos := [2]int32{int32(x >> 4), int32(z >> 4)}
+	lc, ok := w.loadedChunk(pos)
+	if !ok {
+		w.blockUpdates.queueLoad(pos, nil)
+		return 0, false
+	}
+	lc.requested.Store(true)
+	return readBlock(lc, x, y, z)
+}
+
+// blockAt повертає блок в точці x, y, z, тільки якщо чанк вже завантажений
+// На відміну від GetBlock не ставить чанк в чергу, тому нею перевіряють
+// координати від клієнта: інакше будь-хто міг би вантажити і генерувати чанки де завгодно
+func (w *World) blockAt(x, y, z int) (block.StateID, bool) {
+	if y < chunkMinY || y >= chunkMinY+chunkHeight {
+		return 0, false
+	}
+	lc, ok := w.loadedChunk([2]int32{int32(x >> 4), int32(z >> 4)})
+	if !ok {
+		return 0, false
+	}
+	return readBlock(lc, x, y, z)
+}
+
+// loadedChunk шукає завантажений чанк, можна викликати з будь-якої горутини
+func (w *World) loadedChunk(pos [2]int32) (*LoadedChunk, bool) {
+	w.chunksLock.RLock()
+	defer w.chunksLock.RUnlock()
+	lc, ok := w.chunks[pos]
+	return lc, ok
+}
+
+// readBlock читає блок з чанку
+func readBlock(lc *LoadedChunk, x, y, z int) (block.StateID, bool) {
+	lc.Lock()
+	defer lc.Unlock()
+	if lc.Chunk == nil || (y-chunkMinY)>>4 >= len(lc.Sections) {
+		return 0, false
+	}
+	return lc.Sections[(y-chunkMinY)>>4].GetBlock(sectionIndex(x&15, y-chunkMinY, z&15)), true
+}
+
+// SetBlock ставить блок в точці x, y, z
+// Гравці побачать зміну в кінці тіку. Якщо чанк не завантажений,
+// блок поставиться, коли чанк завантажиться. false - y за межами світу
+// true означає тільки, що зміну прийнято: якщо чанк не вдасться завантажити,
+// відкладена зміна відкинеться із записом в лог
+// Можна викликати з будь-якої горутини, в тому числі із задач планувальника
+func (w *World) SetBlock(x, y, z int, state block.StateID) bool {
+	if y < chunkMinY || y >= chunkMinY+chunkHeight {
+		return false
+	}
+	pos := [2]int32{int32(x >> 4), int32(z >> 4)}
+	// Тримаємо chunksLock, щоб чанк не вивантажився між записом і збереженням
+	w.chunksLock.RLock()
+	defer w.chunksLock.RUnlock()
+	lc, ok := w.chunks[pos]
+	if !ok {
+		w.blockUpdates.queueLoad(pos, &pendingBlock{x, y, z, state})
+		return true
+	}
+	lc.requested.Store(true)
+	if !writeBlock(lc, x, y, z, state) {
+		return false
+	}
+	// Записуємо зміну вже після lc.Unlock: blockUpdates блокується раніше за чанки
+	w.blockUpdates.Lock()
+	w.blockUpdates.changed(x, y, z)
+	w.blockUpdates.Unlock()
+	return true
+}
+
+// writeBlock змінює блок в чанку, оновлює карти висот і позначає чанк зміненим
+func writeBlock(lc *LoadedChunk, x, y, z int, state block.StateID) bool {
+	lc.Lock()
+	defer lc.Unlock()
+	if lc.Chunk == nil || (y-chunkMinY)>>4 >= len(lc.Sections) {
+		return false
+	}
+	lc.Sections[(y-chunkMinY)>>4].SetBlock(sectionIndex(x&15, y-chunkMinY, z&15), state)
+	updateHeightMapColumn(lc.Chunk, x&15, z&15)
+	lc.MarkDirty()
+	return true
+}
+
+// queueLoad просить тік завантажити чанк pos, change - зміна, яку застосувати після завантаження
+func (u *blockUpdates) queueLoad(pos [2]int32, change *pendingBlock) {
+	u.Lock()
+	defer u.Unlock()
+	if u.load == nil {
+		u.load = make(map[[2]int32][]pendingBlock)
+	}
+	changes := u.load[pos]
+	if change != nil {
+		changes = append(changes, *change)
+	}
+	u.load[pos] = changes
+}
+
+// dropLoad забуває про чанк, який не вдалося завантажити
+// Повертає скільки відкладених змін блоків відкинуто
+func (u *blockUpdates) dropLoad(pos [2]int32) int {
+	u.Lock()
+	defer u.Unlock()
+	n := len(u.load[pos])
+	delete(u.load, pos)
+	return n
+}
+
+// changed запам'ятовує змінений блок для розсилки в кінці тіку
+// Викликати, коли u заблоковано
+func (u *blockUpdates) changed(x, y, z int) {
+	if u.sections == nil {
+		u.sections = make(map[[3]int32]map[[3]int32]struct{})
+	}
+	sec := [3]int32{int32(x >> 4), int32(y >> 4), int32(z >> 4)}
+	blocks, ok := u.sections[sec]
+	if !ok {
+		blocks = make(map[[3]int32]struct{})
+		u.sections[sec] = blocks
+	}
+	blocks[[3]int32{int32(x), int32(y), int32(z)}] = struct{}{}
+}
+
+// loadBlockChunks ставить в чергу чанки, про які питали GetBlock і SetBlock,
+// і застосовує відкладені зміни до тих, що вже завантажились
+// Викликати тільки під tickLock, після collectLoadedChunks
+func (w *World) loadBlockChunks() {
+	u := &w.blockUpdates
+	u.Lock()
+	defer u.Unlock()
+	for pos, changes := range u.load {
+		switch w.chunkState(pos) {
+		case chunkLoaded:
+			lc := w.chunks[pos]
+			lc.requested.Store(true)
+			for _, c := range changes {
+				if writeBlock(lc, c.x, c.y, c.z, c.state) {
+					u.changed(c.x, c.y, c.z)
+				}
+			}
+			delete(u.load, pos)
+		case chunkUnloaded:
+			w.requestChunk(pos) // не вийшло - спробуємо в наступному тіку
+		}
+	}
+}
+
+// subtickBlockUpdates перераховує світло, розсилає зміни блоків і світла за тік
+// і підтверджує гравцям їх дії з блоками
+// Підтвердження йде після змін, інакше клієнт на мить поверне старий блок
+// Викликати тільки під tickLock
+func (w *World) subtickBlockUpdates() {
+	u := &w.blockUpdates
+	u.Lock()
+	sections := u.sections
+	u.sections = nil
+	u.Unlock()
+
+	var changed [][3]int32
+	for _, blocks := range sections {
+		for pos := range blocks {
+			changed = append(changed, pos)
+		}
+	}
+	w.updateLight(changed)
+
+	for sec, blocks := range sections {
+		lc, ok := w.chunks[[2]int32{sec[0], sec[2]}]
+		if !ok {
+			continue // чанк вже вивантажили
+		}
+		lc.Lock()
+		if len(lc.viewers) > 0 && lc.Chunk != nil {
+			changes := make([]BlockChange, 0, len(blocks))
+			s := &lc.Sections[int(sec[1])-chunkMinY/16]
+			for pos := range blocks {
+				state := s.GetBlock(sectionIndex(int(pos[0]&15), int(pos[1])-chunkMinY, int(pos[2]&15)))
+				changes = append(changes, BlockChange{pos, state})
+			}
+			for _, v := range lc.viewers {
+				if len(changes) == 1 {
+					v.ViewBlockUpdate(changes[0].Pos, changes[0].State)
+				} else {
+					v.ViewSectionBlocksUpdate(sec, changes)
+				}
+			}
+		}
+		lc.Unlock()
+	}
+	w.sendLightUpdates()
+
+	for c, p := range w.players {
+		if p.blockAck > 0 {
+			c.SendBlockChangedAck(p.blockAck)
+			p.blockAck = 0
+		}
+	}
+}
+
+// isWater повертає true якщо блок - вода або рослина, яка росте тільки під водою
+// Блоки з waterlogged=true поки не враховуються
+func isWater(state block.StateID) bool {
+	switch block.StateList[state].(type) {
+	case block.Water, block.BubbleColumn, block.Kelp, block.KelpPlant, block.Seagrass, block.TallSeagrass:
+		return true
+	}
+	return false
+}
+
+// waterAt повертає true якщо в блоці з точкою pos є вода
+func (w *World) waterAt(pos Position) bool {
+	state, ok := w.blockAt(floor(pos[0]), floor(pos[1]), floor(pos[2]))
+	return ok && isWater(state)
+}
+
+// floor округлює координату вниз до блоку
+func floor(v float64) int {
+	return int(math.Floor(v))
+}
@@ -0,0 +1,122 @@
+package world
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/level/block"
+)
+
+func TestSetBlockBatches(t *testing.T) {
+	w, c, _ := newDigWorld()
+	stone, dirt := block.ToStateID[block.Stone{}], block.ToStateID[block.Dirt{}]
+
+	// Три блоки в одній секції - один пакет на секцію, один блок в іншій - звичайний
+	for _, x := range []int{1, 2, 3} {
+		if !w.SetBlock(x, 16, 1, stone) {
+			t.Fatal("SetBlock failed")
+		}
+	}
+	w.SetBlock(1, 15, 1, dirt)
+	if state, ok := w.GetBlock(2, 16, 1); !ok || state != stone {
+		t.Fatalf("GetBlock after SetBlock: %v %v", state, ok)
+	}
+	if len(c.updates) != 0 {
+		t.Fatal("updates sent before the end of the tick")
+	}
+	w.subtickBlockUpdates()
+	if c.sections != 1 || len(c.updates) != 4 || c.updates[[3]int32{1, 15, 1}] != dirt {
+		t.Errorf("sections %d, updates %v", c.sections, c.updates)
+	}
+
+	// Незавантажений чанк ставиться в чергу разом зі зміною
+	if _, ok := w.GetBlock(40, 0, 0); ok {
+		t.Error("GetBlock in an unloaded chunk")
+	}
+	w.SetBlock(40, 0, 0, stone)
+	if changes := w.blockUpdates.load[[2]int32{2, 0}]; len(changes) != 1 || changes[0].state != stone {
+		t.Errorf("queued %v", changes)
+	}
+	if w.SetBlock(0, chunkMinY+chunkHeight, 0, stone) {
+		t.Error("SetBlock above the world")
+	}
+}
+
+func TestBlockChunkLoadFails(t *testing.T) {
+	w := &World{
+		log:         zap.NewNop(),
+		chunks:      make(map[[2]int32]*LoadedChunk),
+		chunkLoader: &chunkLoadPool{results: make(chan chunkLoadResult, 1), pending: make(map[[2]int32]struct{})},
+	}
+	pos := [2]int32{2, 0}
+	w.SetBlock(40, 0, 0, block.ToStateID[block.Stone{}])
+	w.chunkLoader.pending[pos] = struct{}{}
+	w.chunkLoader.results <- chunkLoadResult{pos: pos, err: errors.New("broken region")}
+
+	// Відкладена зміна відкидається, і чанк більше не просять в кожному тіку
+	w.collectLoadedChunks()
+	w.loadBlockChunks()
+	if len(w.blockUpdates.load) != 0 || len(w.chunkLoader.pending) != 0 {
+		t.Errorf("failed chunk is still queued: load %v, pending %v", w.blockUpdates.load, w.chunkLoader.pending)
+	}
+}
+
+func TestBlockChunkKeepAlive(t *testing.T) {
+	p := NewProvider(t.TempDir(), RegionCacheConfig{MaxOpen: 1})
+	defer p.Close()
+	pos := [2]int32{0, 0}
+	w := &World{
+		log:           zap.NewNop(),
+		chunkProvider: p,
+		chunks:        map[[2]int32]*LoadedChunk{pos: {Chunk: level.EmptyChunk(chunkSections)}},
+	}
+
+	// Чанк без гравців, але з GetBlock, живе ще blockChunkKeepAlive перевірок,
+	// а кожен новий запит продовжує йому життя
+	checks := func(n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			w.subtickChunkLoad(nil)
+			if _, ok := w.chunks[pos]; !ok {
+				t.Fatalf("chunk unloaded after %d checks", i+1)
+			}
+		}
+	}
+	w.GetBlock(0, 0, 0)
+	checks(blockChunkKeepAlive / 2)
+	w.GetBlock(1, 0, 0)
+	checks(blockChunkKeepAlive)
+	w.subtickChunkLoad(nil)
+	if _, ok := w.chunks[pos]; ok {
+		t.Error("idle chunk was not unloaded")
+	}
+}
+
+func TestSetBlockUpdatesHeightMaps(t *testing.T) {
+	w, _, _ := newDigWorld()
+	lc := w.chunks[[2]int32{0, 0}]
+	updateHeightMaps(lc.Chunk)
+	hm := lc.HeightMaps
+	top := 15 - chunkMinY + 1 // над каменем, рахуючи від chunkMinY
+	column := func() [2]int { return [2]int{hm.WorldSurface.Get(1*16 + 1), hm.MotionBlocking.Get(1*16 + 1)} }
+
+	// Факел видно на поверхні, але рух він не зупиняє
+	w.SetBlock(1, 16, 1, block.ToStateID[block.Torch{}])
+	if got := column(); got != [2]int{top + 1, top} {
+		t.Errorf("torch: surface/motion %v, want %v", got, [2]int{top + 1, top})
+	}
+	w.SetBlock(1, 20, 1, block.ToStateID[block.Stone{}])
+	if got := column(); got != [2]int{top + 5, top + 5} {
+		t.Errorf("stone: surface/motion %v, want %v", got, [2]int{top + 5, top + 5})
+	}
+	w.SetBlock(1, 20, 1, block.ToStateID[block.Air{}])
+	if got := column(); got != [2]int{top + 1, top} {
+		t.Errorf("after removal: surface/motion %v, want %v", got, [2]int{top + 1, top})
+	}
+	if hasCollision(block.ToStateID[block.Snow{}]) || !hasCollision(block.ToStateID[block.LilyPad{}]) {
+		t.Error("collision table disagrees with vanilla")
+	}
+}
@@ -0,0 +1,189 @@
+// Йоу, чат! Зараз розберемо як чанки завантажуються у фоні!
+// Читання .mca файлу і розбір NBT - це повільно, а тік-горутина тримає tickLock.
+// Якщо вантажити чанки прямо в тіку, всі гравці відчувають лаг.
+// Тому ми віддаємо завантаження пулу воркерів, а готові чанки
+// забираємо назад в тік-горутині, коли вони будуть готові.
+
+package world
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"github.com/Tnze/go-mc/level"
+)
+
+// chunkState - стан чанку з точки зору завантажувачів
+type chunkState int
+
+const (
+	chunkUnloaded chunkState = iota // чанку немає в пам'яті
+	chunkPending                    // чанк завантажується воркером
+	chunkLoaded                     // чанк в w.chunks і готовий до відправки
+)
+
+// chunkLoadResult - результат роботи воркера
+type chunkLoadResult struct {
+	pos       [2]int32
+	chunk     *level.Chunk
+	generated bool // чанку не було на диску і його згенеровано
+	err       error
+}
+
+// chunkLoadPool - пул горутин, які завантажують чанки з диску
+// Мапа pending належить тік-горутині і змінюється тільки під tickLock
+type chunkLoadPool struct {
+	requests chan [2]int32         // черга чанків на завантаження
+	results  chan chunkLoadResult  // готові чанки
+	done     chan struct{}         // закривається при зупинці світу
+	pending  map[[2]int32]struct{} // чанки, які зараз завантажуються
+	limiter  *rate.Limiter         // скільки чанків на секунду можна ставити в чергу
+}
+
+// startChunkLoadPool запускає workers воркерів
+// limiter обмежує швидкість, з якою тік-горутина ставить чанки в чергу
+func (w *World) startChunkLoadPool(workers int, limiter *rate.Limiter) *chunkLoadPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if limiter == nil {
+		limiter = rate.NewLimiter(rate.Inf, workers)
+	}
+	pool := &chunkLoadPool{
+		requests: make(chan [2]int32, workers),
+		results:  make(chan chunkLoadResult, workers*4),
+		done:     make(chan struct{}),
+		pending:  make(map[[2]int32]struct{}),
+		limiter:  limiter,
+	}
+	for i := 0; i < workers; i++ {
+		go w.chunkLoadWorker(pool)
+	}
+	return pool
+}
+
+// chunkLoadWorker бере позиції з черги, завантажує чанки і віддає результат
+// Не чіпає стан світу, тому працює без tickLock
+func (w *World) chunkLoadWorker(pool *chunkLoadPool) {
+	for {
+		select {
+		case <-pool.done:
+			return
+		case pos := <-pool.requests:
+			start := time.Now()
+			c, generated, err := w.readChunk(pos)
+			w.profiler.chunkRead(time.Since(start))
+			if err == nil {
+				lightChunk(pos, c) // поки чанк належить тільки воркеру
+			}
+			select {
+			case pool.results <- chunkLoadResult{pos: pos, chunk: c, generated: generated, err: err}:
+			case <-pool.done:
+				return
+			}
+		}
+	}
+}
+
+// chunkState повертає стан чанку: не завантажений, в процесі чи готовий
+func (w *World) chunkState(pos [2]int32) chunkState {
+	if _, ok := w.chunks[pos]; ok {
+		return chunkLoaded
+	}
+	if _, ok := w.chunkLoader.pending[pos]; ok {
+		return chunkPending
+	}
+	return chunkUnloaded
+}
+
+// requestChunk ставить чанк в чергу на завантаження
+// Повертає false якщо вичерпано глобальний бюджет або черга воркерів заповнена
+// Викликати тільки під tickLock
+func (w *World) requestChunk(pos [2]int32) bool {
+	pool := w.chunkLoader
+	// Резервуємо токен і повертаємо його, якщо черга заповнена,
+	// інакше зайнятий пул працював би повільніше за налаштований ліміт
+	// Cancel з пізнішим часом токен не повертає, тому весь час рахуємо від now
+	now := time.Now()
+	r := pool.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return false
+	}
+	if r.DelayFrom(now) > 0 {
+		r.CancelAt(now)
+		return false
+	}
+	select {
+	case pool.requests <- pos:
+		pool.pending[pos] = struct{}{}
+		return true
+	default:
+		r.CancelAt(now)
+		return false // всі воркери зайняті, спробуємо в наступному тіку
+	}
+}
+
+// collectLoadedChunks забирає готові чанки від воркерів і додає їх в світ
+// Не блокується: забирає тільки те, що вже готово
+// Викликати тільки під tickLock
+func (w *World) collectLoadedChunks() {
+	for {
+		select {
+		case res := <-w.chunkLoader.results:
+			delete(w.chunkLoader.pending, res.pos)
+			if res.err != nil {
+				// Відкладені зміни блоків відкидаємо, інакше loadBlockChunks
+				// просив би цей чанк знову в кожному тіку
+				w.log.Error("Load chunk error",
+					zap.Int32("x", res.pos[0]),
+					zap.Int32("z", res.pos[1]),
+					zap.Int("dropped blocks", w.blockUpdates.dropLoad(res.pos)),
+					zap.Error(res.err))
+				continue
+			}
+			// Зберігаємо чанк в мапі завантажених чанків
+			// Згенерований чанк ще не існує на диску, тому одразу позначаємо його зміненим
+			lc := &LoadedChunk{Chunk: res.chunk}
+			if res.generated {
+				lc.MarkDirty()
+			}
+			w.chunksLock.Lock()
+			w.chunks[res.pos] = lc
+			w.chunksLock.Unlock()
+			w.joinChunkLight(res.pos)
+		default:
+			return
+		}
+	}
+}
+
+// readChunk завантажує чанк за вказаними координатами
+// Якщо чанк не існує - генерує новий
+// Викликається з воркерів, тому не чіпає w.chunks
+func (w *World) readChunk(pos [2]int32) (c *level.Chunk, generated bool, err error) {
+	// Створюємо логер з координатами чанку для зручного дебагу
+	logger := w.log.With(zap.Int32("x", pos[0]), zap.Int32("z", pos[1]))
+	logger.Debug("Loading chunk")
+
+	// Намагаємось завантажити чанк через провайдер
+	c, err = w.chunkProvider.GetChunk(pos)
+	if errors.Is(err, errChunkNotExist) {
+		// Чанк не існує - генеруємо новий
+		logger.Debug("Generate chunk")
+
+		c = w.config.Generator.Generate(pos, w.seed)
+		logger.Debug("Generated chunk", zap.Int("sections", len(c.Sections)))
+		return c, true, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	// Логуємо успішне завантаження
+	logger.Debug("Loaded chunk",
+		zap.Int("sections", len(c.Sections)),
+		zap.String("status", string(c.Status)))
+	return c, false, nil
+}
@@ -0,0 +1,75 @@
+// Йоу, чат! Перевіряємо що чанк проходить шлях "не завантажений -> в процесі -> готовий"
+// і що тік-горутина отримує його від воркера.
+
+package world
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// TestChunkLoadPool завантажує чанк через пул воркерів
+func TestChunkLoadPool(t *testing.T) {
+	p := NewProvider(t.TempDir(), RegionCacheConfig{MaxOpen: 1})
+	defer p.Close()
+	w := &World{
+		log:           zap.NewNop(),
+		config:        Config{Generator: StoneGenerator{}},
+		chunkProvider: p,
+		chunks:        make(map[[2]int32]*LoadedChunk),
+	}
+	w.chunkLoader = w.startChunkLoadPool(2, nil)
+	defer close(w.chunkLoader.done)
+
+	pos := [2]int32{5, -7}
+	if s := w.chunkState(pos); s != chunkUnloaded {
+		t.Fatalf("want unloaded state, got %d", s)
+	}
+	if !w.requestChunk(pos) {
+		t.Fatal("request is rejected")
+	}
+	if s := w.chunkState(pos); s != chunkPending {
+		t.Fatalf("want pending state, got %d", s)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for w.chunkState(pos) != chunkLoaded {
+		if time.Now().After(deadline) {
+			t.Fatal("chunk is not loaded in time")
+		}
+		time.Sleep(time.Millisecond)
+		w.collectLoadedChunks()
+	}
+	if len(w.chunkLoader.pending) != 0 {
+		t.Error("pending set is not cleared")
+	}
+	// На диску чанку не було, тому він згенерований і має бути збережений
+	if !w.chunks[pos].IsDirty() {
+		t.Error("generated chunk is not marked dirty")
+	}
+}
+
+// TestRequestChunkKeepsToken перевіряє, що заповнена черга не з'їдає бюджет лімітера
+func TestRequestChunkKeepsToken(t *testing.T) {
+	w := &World{chunkLoader: &chunkLoadPool{
+		requests: make(chan [2]int32, 1),
+		pending:  make(map[[2]int32]struct{}),
+		limiter:  rate.NewLimiter(rate.Every(time.Hour), 2),
+	}}
+	if !w.requestChunk([2]int32{0, 0}) {
+		t.Fatal("first request is rejected")
+	}
+	if w.requestChunk([2]int32{1, 0}) {
+		t.Fatal("request to a full queue is accepted")
+	}
+	<-w.chunkLoader.requests // воркер забрав чанк
+	if !w.requestChunk([2]int32{1, 0}) {
+		t.Error("full queue used up the limiter token")
+	}
+	if w.requestChunk([2]int32{2, 0}) {
+		t.Error("limiter budget is exceeded")
+	}
+}
@@ -0,0 +1,114 @@
+// Йоу, чат! Зараз розберемо фейкового клієнта для тестів.
+// Він реалізує весь Client: те, що тестам треба перевірити, записується,
+// а решта методів просто нічого не робить, тому непередбачений виклик
+// не падає з panic на nil інтерфейсі.
+
+package world
+
+import (
+	"github.com/Tnze/go-mc/chat"
+	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/level/block"
+
+	"FlowyCore/world/entity"
+)
+
+// fakeClient записує все, що сервер відправив гравцю
+type fakeClient struct {
+	times      [][2]int64 // SendSetTime: вік світу і час доби
+	gameEvents []byte
+	rain       float32 // останній рівень дощу з GameEventRainLevelChange
+	acks       []int32
+
+	updates  map[[3]int32]block.StateID // останній стан кожного зміненого блоку
+	sections int                        // скільки прийшло ClientboundSectionBlocksUpdate
+	lights   int                        // скільки прийшло ClientboundLightUpdate
+
+	added        []int32
+	moved        []int32
+	removed      []int32
+	data         []entity.MetadataSet
+	pos          Position // позиція останньої сутності, відтворена з пакетів як на клієнті
+	teleports    int
+	anims        []Animation
+	entityEvents []EntityEvent
+}
+
+// count рахує, скільки разів прийшла подія гри
+func (c *fakeClient) count(event byte) (n int) {
+	for _, e := range c.gameEvents {
+		if e == event {
+			n++
+		}
+	}
+	return
+}
+
+func (c *fakeClient) setBlock(pos [3]int32, state block.StateID) {
+	if c.updates == nil {
+		c.updates = make(map[[3]int32]block.StateID)
+	}
+	c.updates[pos] = state
+}
+
+func (c *fakeClient) SendDisconnect(chat.Message)                          {}
+func (c *fakeClient) SendPlayerPosition([3]float64, [2]float32) int32      { return 0 }
+func (c *fakeClient) SendSetChunkCacheCenter([2]int32)                     {}
+func (c *fakeClient) SendBlockChangedAck(sequence int32)                   { c.acks = append(c.acks, sequence) }
+func (c *fakeClient) ViewChunkLoad(level.ChunkPos, *level.Chunk)           {}
+func (c *fakeClient) ViewChunkUnload(level.ChunkPos)                       {}
+func (c *fakeClient) ViewBlockUpdate(pos [3]int32, state block.StateID)    { c.setBlock(pos, state) }
+func (c *fakeClient) ViewLightUpdate(level.ChunkPos, *level.Chunk, uint32) { c.lights++ }
+
+func (c *fakeClient) SendSetTime(worldAge, dayTime int64) {
+	c.times = append(c.times, [2]int64{worldAge, dayTime})
+}
+
+func (c *fakeClient) SendGameEvent(event byte, value float32) {
+	c.gameEvents = append(c.gameEvents, event)
+	if event == GameEventRainLevelChange {
+		c.rain = value
+	}
+}
+
+func (c *fakeClient) ViewSectionBlocksUpdate(_ [3]int32, changes []BlockChange) {
+	c.sections++
+	for _, ch := range changes {
+		c.setBlock(ch.Pos, ch.State)
+	}
+}
+
+func (c *fakeClient) ViewAddPlayer(*Player) {}
+
+func (c *fakeClient) ViewAddEntity(e *GenericEntity) {
+	c.added = append(c.added, e.EntityID)
+	c.pos = e.Position
+}
+
+func (c *fakeClient) ViewRemoveEntities(ids []int32) { c.removed = append(c.removed, ids...) }
+
+func (c *fakeClient) ViewMoveEntityPos(id int32, delta [3]int16, _ bool) {
+	c.moved = append(c.moved, id)
+	for i := range delta {
+		c.pos[i] += float64(delta[i]) / 4096
+	}
+}
+
+func (c *fakeClient) ViewMoveEntityPosAndRot(id int32, delta [3]int16, _ [2]int8, onGround bool) {
+	c.ViewMoveEntityPos(id, delta, onGround)
+}
+
+func (c *fakeClient) ViewMoveEntityRot(int32, [2]int8, bool) {}
+func (c *fakeClient) ViewRotateHead(int32, int8)             {}
+
+func (c *fakeClient) ViewTeleportEntity(_ int32, pos [3]float64, _ [2]int8, _ bool) {
+	c.pos = pos
+	c.teleports++
+}
+
+func (c *fakeClient) ViewEntityData(_ int32, data entity.MetadataSet) { c.data = append(c.data, data) }
+func (c *fakeClient) ViewAnimate(_ int32, anim Animation)             { c.anims = append(c.anims, anim) }
+
+func (c *fakeClient) ViewEntityEvent(_ int32, event EntityEvent) {
+	c.entityEvents = append(c.entityEvents, event)
+}
@@ -0,0 +1,82 @@
+// Йоу, чат! Зараз розберемо як в світі змінюється день і ніч!
+// В level.dat є два лічильники часу:
+// - Time - вік світу в тіках, росте завжди
+// - DayTime - час доби, 24000 тіків на повну добу (0 - ранок, 6000 - полудень,
+//   13000 - ніч), росте тільки якщо увімкнене правило doDaylightCycle
+// Клієнт сам рухає сонце між пакетами, тому сервер раз на секунду
+// відправляє ClientboundSetTime, щоб годинники не розійшлися.
+
+package world
+
+import "strconv"
+
+const (
+	// ticksPerDay - тривалість доби в тіках (20 хвилин)
+	ticksPerDay = 24000
+	// timeSyncInterval - як часто синхронізувати час з клієнтами, як у ванілі
+	timeSyncInterval = 20
+	// ruleDaylightCycle - правило гри, яке зупиняє зміну дня і ночі
+	ruleDaylightCycle = "doDaylightCycle"
+)
+
+// boolRule повертає значення булевого правила гри
+// Правила без значення в level.dat вважаються увімкненими, як у ванілі
+// Викликати тільки під tickLock
+func (w *World) boolRule(name string) bool {
+	v, ok := w.level.data.GameRules[name]
+	if !ok {
+		return true
+	}
+	b, err := strconv.ParseBool(v)
+	return err != nil || b
+}
+
+// advanceTime рахує один тік часу світу
+// Викликати тільки під tickLock
+func (w *World) advanceTime() {
+	data := &w.level.data
+	data.Time++
+	if w.boolRule(ruleDaylightCycle) {
+		data.DayTime++
+	}
+}
+
+// worldTime повертає час для пакету ClientboundSetTime
+// Якщо зміна доби вимкнена, час доби відправляється від'ємним:
+// так клієнт знає, що сонце рухати не треба
+// Викликати тільки під tickLock
+func (w *World) worldTime() (age, dayTime int64) {
+	data := &w.level.data
+	dayTime = data.DayTime
+	if !w.boolRule(ruleDaylightCycle) {
+		dayTime = -dayTime
+		if dayTime == 0 {
+			dayTime = -1 // -0 клієнт не відрізнить від увімкненого циклу
+		}
+	}
+	return data.Time, dayTime
+}
+
+// broadcastTime відправляє поточний час всім гравцям
+// Викликати тільки під tickLock
+func (w *World) broadcastTime() {
+	age, dayTime := w.worldTime()
+	for c := range w.players {
+		c.SendSetTime(age, dayTime)
+	}
+}
+
+// Time повертає вік світу і час доби в тіках
+func (w *World) Time() (age, dayTime int64) {
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	return w.level.data.Time, w.level.data.DayTime
+}
+
+// SetDayTime змінює час доби і одразу повідомляє гравців, як команда /time set
+func (w *World) SetDayTime(dayTime int64) {
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	w.level.data.DayTime = dayTime
+	w.broadcastTime()
+}
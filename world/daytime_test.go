@@ -0,0 +1,33 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/save"
+)
+
+func TestWorldTime(t *testing.T) {
+	c := &fakeClient{}
+	w := &World{
+		level:   &Level{data: save.LevelData{Time: 100, DayTime: 23999, GameRules: map[string]string{}}},
+		players: map[Client]*Player{c: {}},
+	}
+
+	w.advanceTime()
+	if w.level.data.Time != 101 || w.level.data.DayTime != 24000 {
+		t.Errorf("time = %d/%d, want 101/24000", w.level.data.Time, w.level.data.DayTime)
+	}
+
+	// Вимкнений цикл доби: вік світу росте, час доби стоїть і відправляється від'ємним
+	w.level.data.GameRules[ruleDaylightCycle] = "false"
+	w.advanceTime()
+	w.broadcastTime()
+	if len(c.times) != 1 || c.times[0] != [2]int64{102, -24000} {
+		t.Errorf("sent %v, want [[102 -24000]]", c.times)
+	}
+
+	w.level.data.DayTime = 0
+	if _, day := w.worldTime(); day != -1 {
+		t.Errorf("frozen midnight sent as %d, want -1", day)
+	}
+}
@@ -0,0 +1,153 @@
+// Йоу, чат! Зараз розберемо як гравець ламає блоки!
+// Клієнт відправляє ServerboundPlayerAction: почав копати, передумав, докопав.
+// В креативі блок ламається одразу на старті, а у виживанні сервер
+// запам'ятовує, коли гравець почав, і на фініші перевіряє, чи можна було
+// вкопати блок за цей час з таким інструментом (див. hardness.go).
+// Як і ваніла, ми пропускаємо 30% часу на лаги.
+//
+// Клієнт ламає блок у себе одразу і чекає ClientboundBlockChangedAck з номером
+// дії. Якщо ми не зламали блок, перед підтвердженням шлемо йому справжній блок,
+// інакше блок зникне тільки в нього.
+
+package world
+
+import "github.com/Tnze/go-mc/level/block"
+
+// DigStatus - дія з ServerboundPlayerAction
+type DigStatus int32
+
+const (
+	DigStart  DigStatus = 0 // почав копати
+	DigCancel DigStatus = 1 // перестав копати, не докопавши
+	DigFinish DigStatus = 2 // докопав
+)
+
+// DigAction - дія гравця з блоком, яка чекає обробки в тіку
+type DigAction struct {
+	Status   DigStatus
+	Pos      [3]int32
+	Sequence int32 // номер дії для ClientboundBlockChangedAck
+}
+
+// digReach - з якої відстані від очей гравець може копати блок
+const digReach = 6
+
+// digLagTolerance - яку частину часу копання пропускаємо на затримку мережі
+const digLagTolerance = 0.7
+
+// digState - блок, який гравець копає у виживанні
+type digState struct {
+	pos   [3]int32
+	start int64 // вік світу, коли гравець почав копати
+}
+
+// subtickBlockActions обробляє дії гравців з блоками
+// Гравці з різних регіонів можуть копати і ставити блоки в одному чанку,
+// а встановлення перевіряє позиції всіх гравців, тому дії з блоками
+// виконуються по черзі, а не в паралельних регіонах
+// Викликати тільки під tickLock
+func (w *World) subtickBlockActions() {
+	for c, p := range w.players {
+		if !p.Inputs.TryLock() {
+			continue // дії почекають до наступного тіку
+		}
+		w.processDigs(c, p)
+		w.processPlaces(c, p)
+		p.Inputs.Unlock()
+	}
+}
+
+// processDigs обробляє дії гравця з блоками з минулого тіку
+// Викликати з subtickBlockActions, коли p.Inputs заблоковані
+func (w *World) processDigs(c Client, p *Player) {
+	if len(p.Inputs.Digs) == 0 {
+		return
+	}
+	ack := p.Inputs.Digs[0].Sequence
+	for _, a := range p.Inputs.Digs {
+		// Повертаємо гравцю блок, який він вже зламав у себе
+		// Задалеко або в незавантаженому чанку - нічого не шлемо і нічого не вантажимо
+		if !w.dig(p, a) && canReach(p, a.Pos) {
+			if state, ok := w.blockAt(int(a.Pos[0]), int(a.Pos[1]), int(a.Pos[2])); ok {
+				c.ViewBlockUpdate(a.Pos, state)
+			}
+		}
+		ack = max(ack, a.Sequence)
+	}
+	p.Inputs.Digs = p.Inputs.Digs[:0]
+	p.blockAck = max(p.blockAck, ack) // підтвердження піде після розсилки змін в кінці тіку
+}
+
+// dig виконує одну дію копання, false - дію відхилено
+func (w *World) dig(p *Player, a DigAction) bool {
+	if a.Status == DigCancel {
+		p.digging = nil
+		return true
+	}
+	// Спершу відстань, а блок читаємо тільки з уже завантаженого чанку
+	x, y, z := int(a.Pos[0]), int(a.Pos[1]), int(a.Pos[2])
+	if !canReach(p, a.Pos) {
+		p.digging = nil
+		return false
+	}
+	state, ok := w.blockAt(x, y, z)
+	if !ok || block.IsAir(state) {
+		p.digging = nil
+		return false
+	}
+
+	switch a.Status {
+	case DigStart:
+		switch p.Gamemode {
+		case 0: // виживання
+		case 1:
+			// В креативі меч не ламає блоки
+			if toolOf(p.Inputs.MainHand()).kind == toolSword {
+				return false
+			}
+			return w.SetBlock(x, y, z, block.ToStateID[block.Air{}])
+		default: // пригоди і спостерігач не ламають блоки
+			return false
+		}
+		if w.digProgress(p, state) >= 1 {
+			p.digging = nil
+			return w.SetBlock(x, y, z, block.ToStateID[block.Air{}])
+		}
+		p.digging = &digState{pos: a.Pos, start: w.level.data.Time}
+		return true
+
+	case DigFinish:
+		d := p.digging
+		p.digging = nil
+		if d == nil || d.pos != a.Pos || p.Gamemode != 0 {
+			return false
+		}
+		ticks := w.level.data.Time - d.start
+		// Блоки поза таблицею перевіряються з міцністю 1, інакше їх можна було б ламати миттєво
+		if w.digProgress(p, state)*float64(ticks+1) < digLagTolerance {
+			return false // копав занадто швидко
+		}
+		return w.SetBlock(x, y, z, block.ToStateID[block.Air{}])
+	}
+	return false
+}
+
+// digProgress повертає, яку частину блоку гравець вкопує за тік
+func (w *World) digProgress(p *Player, state block.StateID) float64 {
+	eyes := p.pos0
+	eyes[1] += eyeHeight
+	return destroyProgress(digInfo(state), p.Inputs.MainHand(), bool(p.OnGround), w.waterAt(eyes))
+}
+
+// canReach перевіряє, чи дотягується гравець до центру блоку
+func canReach(p *Player, pos [3]int32) bool {
+	var dist float64
+	for i := range pos {
+		d := float64(pos[i]) + 0.5 - p.pos0[i]
+		if i == 1 {
+			d -= eyeHeight
+		}
+		dist += d * d
+	}
+	return dist <= digReach*digReach
+}
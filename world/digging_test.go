@@ -0,0 +1,165 @@
+package world
+
+import (
+	"math"
+	"testing"
+
+	"FlowyCore/world/entity"
+	"FlowyCore/world/item"
+	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/level/block"
+	"github.com/Tnze/go-mc/save"
+)
+
+// newDigWorld створює світ з одним кам'яним чанком і гравцем, який стоїть на ньому
+func newDigWorld() (*World, *fakeClient, *Player) {
+	c := level.EmptyChunk(chunkSections)
+	stone := block.ToStateID[block.Stone{}]
+	for i := 0; i < 16*16*16; i++ {
+		c.Sections[4].SetBlock(i, stone) // y від 0 до 15
+	}
+	cl := &fakeClient{}
+	lc := &LoadedChunk{Chunk: c, viewers: []ChunkViewer{cl}}
+	w := &World{
+		level:  &Level{data: save.LevelData{Time: 1000}},
+		chunks: map[[2]int32]*LoadedChunk{{0, 0}: lc},
+	}
+	p := &Player{Entity: Entity{pos0: Position{8.5, 16, 8.5}, OnGround: true}}
+	w.players = map[Client]*Player{cl: p}
+	return w, cl, p
+}
+
+func TestCreativeBreak(t *testing.T) {
+	w, c, p := newDigWorld()
+	p.Gamemode = 1
+	pos := [3]int32{8, 15, 8}
+
+	p.Inputs.Digs = []DigAction{{Status: DigStart, Pos: pos, Sequence: 7}}
+	w.processDigs(c, p)
+	w.subtickBlockUpdates()
+	if state, _ := w.GetBlock(8, 15, 8); !block.IsAir(state) {
+		t.Fatal("creative start did not break the block")
+	}
+	if !block.IsAir(c.updates[pos]) || len(c.acks) != 1 || c.acks[0] != 7 {
+		t.Errorf("updates %v, acks %v", c.updates, c.acks)
+	}
+	if !w.chunks[[2]int32{0, 0}].IsDirty() {
+		t.Error("chunk is not marked dirty")
+	}
+
+	// Мечем в креативі блоки не ламаються, а блок повертається клієнту
+	sword, _ := item.ByName("diamond_sword")
+	p.Inputs.Inventory[36] = entity.Slot{ItemID: sword, Count: 1}
+	pos = [3]int32{8, 14, 8}
+	p.Inputs.Digs = []DigAction{{Status: DigStart, Pos: pos, Sequence: 8}}
+	w.processDigs(c, p)
+	w.subtickBlockUpdates()
+	if state, _ := w.GetBlock(8, 14, 8); block.IsAir(state) || c.updates[pos] != state {
+		t.Errorf("sword broke the block or did not resend it: %v", c.updates[pos])
+	}
+}
+
+func TestSurvivalBreakTime(t *testing.T) {
+	w, c, p := newDigWorld()
+	pos := [3]int32{8, 15, 8}
+	stone := block.ToStateID[block.Stone{}]
+
+	// Камінь рукою: 1 / 1.5 / 100 за тік, тобто 150 тіків
+	p.Inputs.Digs = []DigAction{{Status: DigStart, Pos: pos}, {Status: DigFinish, Pos: pos, Sequence: 1}}
+	w.processDigs(c, p)
+	w.subtickBlockUpdates()
+	if state, _ := w.GetBlock(8, 15, 8); state != stone || c.updates[pos] != stone {
+		t.Fatal("instant finish was accepted")
+	}
+
+	// Дерев'яна кирка: 2 / 1.5 / 30 за тік, тобто 23 тіки, з пропуском на лаги - 16
+	pickaxe, _ := item.ByName("wooden_pickaxe")
+	p.Inputs.Inventory[36] = entity.Slot{ItemID: pickaxe, Count: 1}
+	p.Inputs.Digs = []DigAction{{Status: DigStart, Pos: pos, Sequence: 2}}
+	w.processDigs(c, p)
+	w.subtickBlockUpdates()
+	w.level.data.Time += 15
+	p.Inputs.Digs = []DigAction{{Status: DigFinish, Pos: pos, Sequence: 3}}
+	w.processDigs(c, p)
+	w.subtickBlockUpdates()
+	if state, _ := w.GetBlock(8, 15, 8); !block.IsAir(state) {
+		t.Error("legit finish was rejected")
+	}
+	if len(c.acks) != 3 || c.acks[2] != 3 {
+		t.Errorf("acks %v", c.acks)
+	}
+
+	// Блок поза таблицею міцності ламається як блок з міцністю 1, а не миттєво
+	melon := block.ToStateID[block.Melon{}]
+	w.SetBlock(8, 14, 8, melon)
+	below := [3]int32{8, 14, 8}
+	p.Inputs.Digs = []DigAction{{Status: DigStart, Pos: below}, {Status: DigFinish, Pos: below, Sequence: 4}}
+	w.processDigs(c, p)
+	w.subtickBlockUpdates()
+	if state, _ := w.GetBlock(8, 14, 8); state != melon {
+		t.Error("instant finish on an unknown block was accepted")
+	}
+
+	// Задалеко від очей
+	p.Inputs.Digs = []DigAction{{Status: DigStart, Pos: [3]int32{8, 0, 8}}}
+	w.processDigs(c, p)
+	w.subtickBlockUpdates()
+	if state, _ := w.GetBlock(8, 0, 8); state != stone {
+		t.Error("broke a block out of reach")
+	}
+}
+
+func TestDestroyProgress(t *testing.T) {
+	diamond, _ := item.ByName("diamond_pickaxe")
+	pickaxe := entity.Slot{ItemID: diamond, Count: 1}
+	stone := digInfo(block.ToStateID[block.Stone{}])
+	obsidian := digInfo(block.ToStateID[block.Obsidian{}])
+	for _, tt := range []struct {
+		name     string
+		info     blockInfo
+		held     entity.Slot
+		onGround bool
+		want     float64
+	}{
+		{"stone by hand", stone, entity.Slot{}, true, 1.0 / 1.5 / 100},
+		{"stone by diamond pickaxe", stone, pickaxe, true, 8 / 1.5 / 30},
+		{"stone in the air", stone, pickaxe, false, 8.0 / 5 / 1.5 / 30},
+		{"obsidian by hand", obsidian, entity.Slot{}, true, 1.0 / 50 / 100},
+		{"oak stairs", digInfo(block.ToStateID[block.OakStairs{Facing: 2, Half: 1}]), entity.Slot{}, true, 1.0 / 2 / 30},
+		{"bedrock", digInfo(block.ToStateID[block.Bedrock{}]), pickaxe, true, 0},
+		{"grass", digInfo(block.ToStateID[block.Grass{}]), entity.Slot{}, true, 1},
+	} {
+		if got := destroyProgress(tt.info, tt.held, tt.onGround, false); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: progress %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBlockActionsDoNotLoadChunks(t *testing.T) {
+	w, c, p := newDigWorld()
+	p.Gamemode = 1
+	stone, _ := item.ByName("stone")
+	p.Inputs.Inventory[36] = entity.Slot{ItemID: stone, Count: 1}
+
+	// Клієнт може прислати будь-які координати, але чанки від цього не вантажаться
+	far := [3]int32{100000, 0, 100000}
+	p.Inputs.Digs = []DigAction{{Status: DigStart, Pos: far, Sequence: 1}}
+	p.Inputs.Places = []PlaceAction{{Pos: far, Face: block.Up, Sequence: 2}}
+	w.processDigs(c, p)
+	w.processPlaces(c, p)
+	w.subtickBlockUpdates()
+	if len(w.blockUpdates.load) != 0 {
+		t.Errorf("queued chunk loads %v", w.blockUpdates.load)
+	}
+	if len(c.updates) != 0 || len(c.acks) != 1 || c.acks[0] != 2 {
+		t.Errorf("updates %v, acks %v", c.updates, c.acks)
+	}
+
+	// Поруч, але в сусідньому незавантаженому чанку
+	p.pos0 = Position{1, 16, 8}
+	p.Inputs.Digs = []DigAction{{Status: DigStart, Pos: [3]int32{-2, 16, 8}, Sequence: 3}}
+	w.processDigs(c, p)
+	if len(w.blockUpdates.load) != 0 || len(c.updates) != 0 {
+		t.Errorf("dig next to an unloaded chunk: loads %v, updates %v", w.blockUpdates.load, c.updates)
+	}
+}
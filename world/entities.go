@@ -0,0 +1,153 @@
+// Йоу, чат! Зараз розберемо як в світі з'являються сутності, які не є гравцями!
+// Моби, предмети на землі, стійки для броні, стріли - все це GenericEntity.
+// Кожна така сутність має тип (EntityType): ID типу в протоколі, назву
+// і радіус відстеження - з якої відстані гравці її бачать.
+// Стрілу видно тільки зблизька, а корову - майже через всю зону прогрузки.
+//
+// Сутності живуть в реєстрі світу (World.entities) і оновлюються в тому ж
+// підтіку, що й гравці: через BVH дерево шукаємо гравців, які їх бачать,
+// і відправляємо появу (ClientboundAddEntity) та рухи.
+// На диск сутності поки не зберігаються.
+
+package world
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// EntityType - тип сутності
+type EntityType struct {
+	ID            int32  // ID типу в реєстрі minecraft:entity_type (протокол 1.19.4)
+	Name          string // назва, наприклад minecraft:zombie
+	TrackingRange int32  // з якої відстані в чанках гравці бачать сутність
+}
+
+// Типи сутностей, радіуси відстеження взяті з ванільного EntityType
+var (
+	EntityArmorStand   = &EntityType{ID: 2, Name: "minecraft:armor_stand", TrackingRange: 10}
+	EntityArrow        = &EntityType{ID: 3, Name: "minecraft:arrow", TrackingRange: 4}
+	EntityChicken      = &EntityType{ID: 15, Name: "minecraft:chicken", TrackingRange: 10}
+	EntityCow          = &EntityType{ID: 18, Name: "minecraft:cow", TrackingRange: 10}
+	EntityCreeper      = &EntityType{ID: 19, Name: "minecraft:creeper", TrackingRange: 8}
+	EntityEnderPearl   = &EntityType{ID: 28, Name: "minecraft:ender_pearl", TrackingRange: 4}
+	EntityFallingBlock = &EntityType{ID: 36, Name: "minecraft:falling_block", TrackingRange: 10}
+	EntityItem         = &EntityType{ID: 54, Name: "minecraft:item", TrackingRange: 6}
+	EntityPig          = &EntityType{ID: 72, Name: "minecraft:pig", TrackingRange: 10}
+	EntitySheep        = &EntityType{ID: 82, Name: "minecraft:sheep", TrackingRange: 10}
+	EntitySkeleton     = &EntityType{ID: 86, Name: "minecraft:skeleton", TrackingRange: 8}
+	EntitySnowball     = &EntityType{ID: 92, Name: "minecraft:snowball", TrackingRange: 4}
+	EntitySpider       = &EntityType{ID: 95, Name: "minecraft:spider", TrackingRange: 8}
+	EntityTNT          = &EntityType{ID: 101, Name: "minecraft:tnt", TrackingRange: 10}
+	EntityTrident      = &EntityType{ID: 104, Name: "minecraft:trident", TrackingRange: 4}
+	EntityVillager     = &EntityType{ID: 108, Name: "minecraft:villager", TrackingRange: 10}
+	EntityZombie       = &EntityType{ID: 118, Name: "minecraft:zombie", TrackingRange: 8}
+)
+
+// entityTypes - всі відомі типи за назвою
+var entityTypes = make(map[string]*EntityType)
+
+func init() {
+	for _, t := range []*EntityType{
+		EntityArmorStand, EntityArrow, EntityChicken, EntityCow, EntityCreeper,
+		EntityEnderPearl, EntityFallingBlock, EntityItem, EntityPig, EntitySheep,
+		EntitySkeleton, EntitySnowball, EntitySpider, EntityTNT, EntityTrident,
+		EntityVillager, EntityZombie,
+	} {
+		entityTypes[t.Name] = t
+	}
+}
+
+// EntityTypeByName шукає тип сутності за назвою
+func EntityTypeByName(name string) (*EntityType, bool) {
+	t, ok := entityTypes[name]
+	return t, ok
+}
+
+// GenericEntity - сутність, яка не є гравцем
+type GenericEntity struct {
+	Entity
+	Type     *EntityType
+	UUID     uuid.UUID
+	Data     int32    // додаткові дані для ClientboundAddEntity, наприклад стан блоку falling_block
+	Velocity [3]int16 // швидкість в 1/8000 блоку за тік
+}
+
+// NewGenericEntity створює сутність з новим ID та UUID
+// Сутність з'явиться у гравців тільки після World.AddEntity
+func NewGenericEntity(t *EntityType, pos Position, rot Rotation) *GenericEntity {
+	e := &GenericEntity{
+		Entity: Entity{
+			EntityID: NewEntityID(),
+			Position: pos,
+			Rotation: rot,
+			pos0:     pos,
+			rot0:     rot,
+		},
+		Type: t,
+		UUID: uuid.New(),
+	}
+	e.trackRange = float64(t.TrackingRange) * 16
+	return e
+}
+
+// MoveTo задає позицію, куди сутність переміститься в наступному тіку
+// Викликати тільки з тік-горутини, наприклад із задачі планувальника
+func (e *GenericEntity) MoveTo(pos Position, rot Rotation, onGround bool) {
+	e.pos0, e.rot0, e.OnGround = pos, rot, OnGround(onGround)
+}
+
+// inTrackingRange перевіряє, чи бачить гравець в точці viewer сутність
+// Як у ванілі, відстань рахується окремо по x і z
+func (e *Entity) inTrackingRange(viewer Position) bool {
+	if e.trackRange == 0 {
+		return true
+	}
+	return math.Abs(viewer[0]-e.Position[0]) <= e.trackRange &&
+		math.Abs(viewer[2]-e.Position[2]) <= e.trackRange
+}
+
+// AddEntity додає сутність в світ
+// Гравці поруч побачать її в наступному тіку
+func (w *World) AddEntity(e *GenericEntity) {
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	w.addEntity(e)
+}
+
+// addEntity - AddEntity для коду, який вже тримає tickLock
+func (w *World) addEntity(e *GenericEntity) {
+	if w.entities == nil {
+		w.entities = make(map[int32]*GenericEntity)
+	}
+	w.entities[e.EntityID] = e
+}
+
+// RemoveEntity прибирає сутність зі світу і в усіх гравців, які її бачать
+func (w *World) RemoveEntity(e *GenericEntity) {
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	w.removeEntity(e)
+}
+
+// removeEntity - RemoveEntity для коду, який вже тримає tickLock
+func (w *World) removeEntity(e *GenericEntity) {
+	if _, ok := w.entities[e.EntityID]; !ok {
+		return
+	}
+	delete(w.entities, e.EntityID)
+	for c, p := range w.players {
+		if _, ok := p.EntitiesInView[e.EntityID]; ok {
+			delete(p.EntitiesInView, e.EntityID)
+			c.ViewRemoveEntities([]int32{e.EntityID})
+		}
+	}
+}
+
+// Entity повертає сутність за ID, або nil якщо такої немає
+func (w *World) Entity(id int32) *GenericEntity {
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	return w.entities[id]
+}
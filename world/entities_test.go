@@ -0,0 +1,79 @@
+package world
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+
+	"FlowyCore/world/entity"
+)
+
+func TestEntityTracking(t *testing.T) {
+	w := &World{
+		log:      zap.NewNop(),
+		players:  make(map[Client]*Player),
+		entities: make(map[int32]*GenericEntity),
+	}
+	c := &fakeClient{}
+	p := &Player{
+		Entity:         Entity{Position: [3]float64{0, 64, 0}},
+		ViewDistance:   10,
+		EntitiesInView: make(map[int32]*Entity),
+	}
+	p.Inputs.Position = p.Position
+	p.view = w.playerViews.Insert(p.getView(), playerView{c, p})
+	w.players[c] = p
+
+	// Стріла в зоні прогрузки, але далі її радіусу відстеження (4 чанки)
+	arrow := NewGenericEntity(EntityArrow, Position{100, 64, 0}, Rotation{})
+	arrow.Metadata.SetFlag(entity.FlagOnFire, true)
+	w.addEntity(arrow)
+	tick := func() {
+		w.subtickUpdatePlayers(&tickRegion{clients: []Client{c}})
+		w.subtickUpdateEntities(&tickRegion{entities: []*GenericEntity{arrow}})
+	}
+	tick()
+	if len(c.added) != 0 {
+		t.Fatalf("arrow out of tracking range was spawned: %v", c.added)
+	}
+
+	arrow.MoveTo(Position{50, 64, 0}, Rotation{}, false)
+	tick()
+	if len(c.added) != 1 || c.added[0] != arrow.EntityID || p.EntitiesInView[arrow.EntityID] == nil {
+		t.Fatalf("arrow in range was not spawned: %v", c.added)
+	}
+	// Разом з появою приходять всі метадані, хоча змінились вони ще до того
+	if len(c.data) != 1 || len(c.data[0]) != 1 || arrow.Metadata.Dirty() {
+		t.Fatalf("spawn metadata: %v", c.data)
+	}
+
+	// Змінене поле приходить один раз
+	arrow.Metadata.SetFlag(entity.FlagGlowing, true)
+	tick()
+	tick()
+	if len(c.data) != 2 || c.data[1][0].Index != entity.IndexFlags {
+		t.Errorf("dirty metadata: %v", c.data)
+	}
+
+	arrow.MoveTo(Position{51, 64, 0}, Rotation{}, false)
+	tick()
+	if len(c.moved) != 1 {
+		t.Errorf("moves sent: %v, want one", c.moved)
+	}
+
+	// Відлетіла за радіус - зникає в гравця
+	arrow.MoveTo(Position{90, 64, 0}, Rotation{}, false)
+	tick()
+	tick()
+	if len(c.removed) != 1 || p.EntitiesInView[arrow.EntityID] != nil {
+		t.Fatalf("arrow out of range was not removed: %v", c.removed)
+	}
+
+	// Повернулась і була видалена зі світу
+	arrow.MoveTo(Position{10, 64, 0}, Rotation{}, false)
+	tick()
+	w.removeEntity(arrow)
+	if len(c.added) != 2 || len(c.removed) != 2 || w.entities[arrow.EntityID] != nil {
+		t.Errorf("added %v, removed %v after RemoveEntity", c.added, c.removed)
+	}
+}
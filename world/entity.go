@@ -24,6 +24,8 @@ package world
 import (
 	"math"
 	"sync/atomic"
+
+	"FlowyCore/world/entity"
 )
 
 // entityCounter - атомарний лічильник для генерації унікальних ID сутностей
@@ -50,6 +52,38 @@ type Entity struct {
 	OnGround          // чи на землі
 	pos0     Position // попередня позиція
 	rot0     Rotation // попередній поворот
+
+	trackRange float64 // з якої відстані в блоках сутність видно, 0 = вся зона видимості
+	sinceSync  int     // скільки тіків глядачі не отримували точну позицію
+
+	// Metadata - поза, прапорці, ім'я і т.д.
+	// Змінені поля відправляються гравцям раз на тік
+	Metadata entity.Metadata
+}
+
+// entityResyncInterval - як часто глядачі отримують точну позицію сутності замість відносного руху
+// 400 тіків (20 секунд), як у ванільному ServerEntity
+const entityResyncInterval = 400
+
+// moveDelta рахує відносний рух для ClientboundMoveEntity* в 1/4096 блоку
+// Обидві позиції спершу округлюються до 1/4096, тому помилки округлення не накопичуються:
+// клієнт, який додає всі дельти, опиниться рівно в округленій новій позиції
+// false - рух не влазить в int16 (далі ~8 блоків), треба телепортувати
+func moveDelta(from, to Position) (delta [3]int16, ok bool) {
+	for i := range delta {
+		d := math.Round(to[i]*4096) - math.Round(from[i]*4096)
+		if d < math.MinInt16 || d > math.MaxInt16 {
+			return delta, false
+		}
+		delta[i] = int16(d)
+	}
+	return delta, true
+}
+
+// ToAngle переводить градуси в 1/256 оберту для пакетів
+// Кут спершу береться по модулю 256, тому і -90, і 270 градусів кодуються однаково
+func ToAngle(deg float32) int8 {
+	return int8(int32(math.Floor(float64(deg) * 256 / 360)))
 }
 
 // Position - позиція у 3D просторі
@@ -27,6 +27,8 @@ package entity
 import (
 	"io"
 
+	"github.com/Tnze/go-mc/chat"
+	"github.com/Tnze/go-mc/nbt"
 	pk "github.com/Tnze/go-mc/net/packet"
 )
 
@@ -55,6 +57,7 @@ func (m MetadataSet) WriteTo(w io.Writer) (n int64, err error) {
 		}
 		// Записуємо значення
 		tmpN, err = v.WriteTo(w)
+		n += tmpN
 		if err != nil {
 			return
 		}
@@ -86,26 +89,113 @@ type MetadataValue interface {
 	pk.Field       // Інтерфейс для запису в пакет
 }
 
-// Різні типи метаданих:
+// Різні типи метаданих, ID типів відповідають протоколу 1.19.4
 type (
-	Byte struct{ pk.Byte } // Для маленьких чисел (0-255)
-	// VarInt для великих чисел
-	// Float для дробових чисел
-	// String для тексту
-	// Chat для повідомлень в чаті
-	// OptionalChat для необов'язкових повідомлень
-	// Slot для предметів
-	// Boolean для true/false
-	// Rotation для кутів повороту
-	// Position для координат
+	Byte         struct{ pk.Byte }      // Для маленьких чисел (0-255)
+	VarInt       int32                  // Для великих чисел
+	VarLong      int64                  // Для ще більших чисел
+	Float        float32                // Для дробових чисел
+	String       string                 // Для тексту
+	Chat         struct{ chat.Message } // Для повідомлень в чаті
+	OptionalChat struct {
+		pk.Option[chat.Message, *chat.Message]
+	} // Для необов'язкових повідомлень
+	Boolean     bool                  // Для true/false
+	Rotation    [3]float32            // Для кутів повороту по x, y, z
+	Position    struct{ pk.Position } // Для координат блоку
+	OptPosition struct {
+		pk.Option[pk.Position, *pk.Position]
+	} // Для необов'язкових координат
+	Direction       int32                                  // Для сторони блоку
+	OptUUID         struct{ pk.Option[pk.UUID, *pk.UUID] } // Для необов'язкового UUID, наприклад власника
+	BlockState      int32                                  // Для стану блоку
+	OptBlockState   int32                                  // Для необов'язкового стану блоку, 0 = немає
+	CatVariant      int32                                  // Для породи кота
+	FrogVariant     int32                                  // Для породи жаби
+	PaintingVariant int32                                  // Для картини
+	SnifferState    int32                                  // Для стану нюхача
+	Vector3         [3]float32                             // Для масштабу і зсуву дисплеїв
+	Quaternion      [4]float32                             // Для повороту дисплеїв
 
 	// Pose - поза сутності
 	Pose int32
 )
 
+// Slot - предмет, наприклад в рамці чи на землі
+// Count == 0 означає порожній слот
+type Slot struct {
+	ItemID int32
+	Count  int8
+	NBT    nbt.RawMessage // теги предмета, можна не заповнювати
+}
+
+// NBT - довільні NBT дані, наприклад плече папуги
+type NBT struct{ nbt.RawMessage }
+
+// Particle - частинка з її параметрами
+// Data - параметри частинки (колір пилу, блок і т.д.), nil для частинок без параметрів
+type Particle struct {
+	ID   int32
+	Data pk.FieldEncoder
+}
+
+// VillagerData - тип, професія і рівень жителя
+type VillagerData struct {
+	Type, Profession, Level int32
+}
+
+// OptVarInt - необов'язкове число, наприклад ID сутності, в яку летить феєрверк
+type OptVarInt struct {
+	Has bool
+	Val int32
+}
+
+// OptGlobalPos - необов'язкова позиція в іншому вимірі, наприклад місце смерті
+type OptGlobalPos struct {
+	Has       bool
+	Dimension string
+	Pos       pk.Position
+}
+
+// Сторони для Direction
+const (
+	Down Direction = iota
+	Up
+	North
+	South
+	West
+	East
+)
+
 // TypeID повертає ID типу даних
-func (b *Byte) TypeID() int32 { return 0 }  // Байт = тип 0
-func (p *Pose) TypeID() int32 { return 18 } // Поза = тип 18
+func (b *Byte) TypeID() int32            { return 0 } // Байт = тип 0
+func (v *VarInt) TypeID() int32          { return 1 }
+func (v *VarLong) TypeID() int32         { return 2 }
+func (f *Float) TypeID() int32           { return 3 }
+func (s *String) TypeID() int32          { return 4 }
+func (c *Chat) TypeID() int32            { return 5 }
+func (c *OptionalChat) TypeID() int32    { return 6 }
+func (s *Slot) TypeID() int32            { return 7 }
+func (b *Boolean) TypeID() int32         { return 8 }
+func (r *Rotation) TypeID() int32        { return 9 }
+func (p *Position) TypeID() int32        { return 10 }
+func (p *OptPosition) TypeID() int32     { return 11 }
+func (d *Direction) TypeID() int32       { return 12 }
+func (u *OptUUID) TypeID() int32         { return 13 }
+func (b *BlockState) TypeID() int32      { return 14 }
+func (b *OptBlockState) TypeID() int32   { return 15 }
+func (n *NBT) TypeID() int32             { return 16 }
+func (p *Particle) TypeID() int32        { return 17 }
+func (v *VillagerData) TypeID() int32    { return 18 }
+func (v *OptVarInt) TypeID() int32       { return 19 }
+func (p *Pose) TypeID() int32            { return 20 } // Поза = тип 20
+func (c *CatVariant) TypeID() int32      { return 21 }
+func (f *FrogVariant) TypeID() int32     { return 22 }
+func (g *OptGlobalPos) TypeID() int32    { return 23 }
+func (p *PaintingVariant) TypeID() int32 { return 24 }
+func (s *SnifferState) TypeID() int32    { return 25 }
+func (v *Vector3) TypeID() int32         { return 26 }
+func (q *Quaternion) TypeID() int32      { return 27 }
 
 // Всі можливі пози сутності
 const (
@@ -119,12 +209,40 @@ const (
 	Dying                   // Помирає
 	Croaking                // Квакає (жаба)
 	UsingTongue             // Використовує язик (жаба)
+	Sitting                 // Сидить (верблюд)
 	Roaring                 // Реве (варден)
 	Sniffing                // Нюхає (варден)
 	Emerging                // Виходить з землі (варден)
 	Digging                 // Копає (варден)
 )
 
+// Прості числові типи записуються як VarInt, VarLong, Float чи String з пакету pk
+
+func (v VarInt) WriteTo(w io.Writer) (int64, error)            { return pk.VarInt(v).WriteTo(w) }
+func (v *VarInt) ReadFrom(r io.Reader) (int64, error)          { return (*pk.VarInt)(v).ReadFrom(r) }
+func (v VarLong) WriteTo(w io.Writer) (int64, error)           { return pk.VarLong(v).WriteTo(w) }
+func (v *VarLong) ReadFrom(r io.Reader) (int64, error)         { return (*pk.VarLong)(v).ReadFrom(r) }
+func (f Float) WriteTo(w io.Writer) (int64, error)             { return pk.Float(f).WriteTo(w) }
+func (f *Float) ReadFrom(r io.Reader) (int64, error)           { return (*pk.Float)(f).ReadFrom(r) }
+func (s String) WriteTo(w io.Writer) (int64, error)            { return pk.String(s).WriteTo(w) }
+func (s *String) ReadFrom(r io.Reader) (int64, error)          { return (*pk.String)(s).ReadFrom(r) }
+func (b Boolean) WriteTo(w io.Writer) (int64, error)           { return pk.Boolean(b).WriteTo(w) }
+func (b *Boolean) ReadFrom(r io.Reader) (int64, error)         { return (*pk.Boolean)(b).ReadFrom(r) }
+func (d Direction) WriteTo(w io.Writer) (int64, error)         { return pk.VarInt(d).WriteTo(w) }
+func (d *Direction) ReadFrom(r io.Reader) (int64, error)       { return (*pk.VarInt)(d).ReadFrom(r) }
+func (b BlockState) WriteTo(w io.Writer) (int64, error)        { return pk.VarInt(b).WriteTo(w) }
+func (b *BlockState) ReadFrom(r io.Reader) (int64, error)      { return (*pk.VarInt)(b).ReadFrom(r) }
+func (b OptBlockState) WriteTo(w io.Writer) (int64, error)     { return pk.VarInt(b).WriteTo(w) }
+func (b *OptBlockState) ReadFrom(r io.Reader) (int64, error)   { return (*pk.VarInt)(b).ReadFrom(r) }
+func (c CatVariant) WriteTo(w io.Writer) (int64, error)        { return pk.VarInt(c).WriteTo(w) }
+func (c *CatVariant) ReadFrom(r io.Reader) (int64, error)      { return (*pk.VarInt)(c).ReadFrom(r) }
+func (f FrogVariant) WriteTo(w io.Writer) (int64, error)       { return pk.VarInt(f).WriteTo(w) }
+func (f *FrogVariant) ReadFrom(r io.Reader) (int64, error)     { return (*pk.VarInt)(f).ReadFrom(r) }
+func (p PaintingVariant) WriteTo(w io.Writer) (int64, error)   { return pk.VarInt(p).WriteTo(w) }
+func (p *PaintingVariant) ReadFrom(r io.Reader) (int64, error) { return (*pk.VarInt)(p).ReadFrom(r) }
+func (s SnifferState) WriteTo(w io.Writer) (int64, error)      { return pk.VarInt(s).WriteTo(w) }
+func (s *SnifferState) ReadFrom(r io.Reader) (int64, error)    { return (*pk.VarInt)(s).ReadFrom(r) }
+
 // WriteTo записує позу в пакет
 func (p Pose) WriteTo(w io.Writer) (n int64, err error) {
 	return pk.VarInt(p).WriteTo(w)
@@ -134,3 +252,133 @@ func (p Pose) WriteTo(w io.Writer) (n int64, err error) {
 func (p *Pose) ReadFrom(r io.Reader) (n int64, err error) {
 	return (*pk.VarInt)(p).ReadFrom(r)
 }
+
+// floats записує і читає кілька Float підряд, для Rotation, Vector3 і Quaternion
+type floats []float32
+
+func (f floats) WriteTo(w io.Writer) (n int64, err error) {
+	for _, v := range f {
+		n1, err := pk.Float(v).WriteTo(w)
+		n += n1
+		if err != nil {
+			return n, err
+		}
+	}
+	return
+}
+
+func (f floats) ReadFrom(r io.Reader) (n int64, err error) {
+	for i := range f {
+		n1, err := (*pk.Float)(&f[i]).ReadFrom(r)
+		n += n1
+		if err != nil {
+			return n, err
+		}
+	}
+	return
+}
+
+func (v Rotation) WriteTo(w io.Writer) (int64, error)     { return floats(v[:]).WriteTo(w) }
+func (v *Rotation) ReadFrom(r io.Reader) (int64, error)   { return floats(v[:]).ReadFrom(r) }
+func (v Vector3) WriteTo(w io.Writer) (int64, error)      { return floats(v[:]).WriteTo(w) }
+func (v *Vector3) ReadFrom(r io.Reader) (int64, error)    { return floats(v[:]).ReadFrom(r) }
+func (q Quaternion) WriteTo(w io.Writer) (int64, error)   { return floats(q[:]).WriteTo(w) }
+func (q *Quaternion) ReadFrom(r io.Reader) (int64, error) { return floats(q[:]).ReadFrom(r) }
+
+// WriteTo записує предмет: прапорець наявності, ID, кількість і NBT
+func (s Slot) WriteTo(w io.Writer) (int64, error) {
+	present := pk.Boolean(s.Count > 0)
+	if !present {
+		return present.WriteTo(w)
+	}
+	var tags pk.FieldEncoder = pk.NBT(s.NBT)
+	if s.NBT.Type == nbt.TagEnd {
+		tags = pk.Byte(nbt.TagEnd) // предмет без тегів
+	}
+	return pk.Tuple{present, pk.VarInt(s.ItemID), pk.Byte(s.Count), tags}.WriteTo(w)
+}
+
+// ReadFrom читає предмет
+func (s *Slot) ReadFrom(r io.Reader) (int64, error) {
+	var present pk.Boolean
+	*s = Slot{}
+	return pk.Tuple{
+		&present, pk.Opt{
+			Has:   &present,
+			Field: pk.Tuple{(*pk.VarInt)(&s.ItemID), (*pk.Byte)(&s.Count), pk.NBT(&s.NBT)},
+		},
+	}.ReadFrom(r)
+}
+
+// WriteTo записує NBT
+func (n NBT) WriteTo(w io.Writer) (int64, error) {
+	return pk.NBT(n.RawMessage).WriteTo(w)
+}
+
+// ReadFrom читає NBT
+func (n *NBT) ReadFrom(r io.Reader) (int64, error) {
+	return pk.NBT(&n.RawMessage).ReadFrom(r)
+}
+
+// WriteTo записує ID частинки і її параметри
+func (p Particle) WriteTo(w io.Writer) (int64, error) {
+	n, err := pk.VarInt(p.ID).WriteTo(w)
+	if err != nil || p.Data == nil {
+		return n, err
+	}
+	n1, err := p.Data.WriteTo(w)
+	return n + n1, err
+}
+
+// ReadFrom читає тільки ID частинки
+// Формат параметрів залежить від частинки, тому Data завжди nil,
+// а параметри (якщо вони є) залишаються в потоці
+func (p *Particle) ReadFrom(r io.Reader) (int64, error) {
+	p.Data = nil
+	return (*pk.VarInt)(&p.ID).ReadFrom(r)
+}
+
+// WriteTo записує дані жителя
+func (v VillagerData) WriteTo(w io.Writer) (int64, error) {
+	return pk.Tuple{pk.VarInt(v.Type), pk.VarInt(v.Profession), pk.VarInt(v.Level)}.WriteTo(w)
+}
+
+// ReadFrom читає дані жителя
+func (v *VillagerData) ReadFrom(r io.Reader) (int64, error) {
+	return pk.Tuple{(*pk.VarInt)(&v.Type), (*pk.VarInt)(&v.Profession), (*pk.VarInt)(&v.Level)}.ReadFrom(r)
+}
+
+// WriteTo записує число: 0 - немає значення, інакше значення + 1
+func (v OptVarInt) WriteTo(w io.Writer) (int64, error) {
+	if !v.Has {
+		return pk.VarInt(0).WriteTo(w)
+	}
+	return pk.VarInt(v.Val + 1).WriteTo(w)
+}
+
+// ReadFrom читає необов'язкове число
+func (v *OptVarInt) ReadFrom(r io.Reader) (int64, error) {
+	var raw pk.VarInt
+	n, err := raw.ReadFrom(r)
+	v.Has, v.Val = raw != 0, int32(raw)-1
+	if !v.Has {
+		v.Val = 0
+	}
+	return n, err
+}
+
+// WriteTo записує позицію з виміром
+func (g OptGlobalPos) WriteTo(w io.Writer) (int64, error) {
+	return pk.Tuple{
+		pk.Boolean(g.Has),
+		pk.Opt{Has: g.Has, Field: pk.Tuple{pk.Identifier(g.Dimension), g.Pos}},
+	}.WriteTo(w)
+}
+
+// ReadFrom читає позицію з виміром
+func (g *OptGlobalPos) ReadFrom(r io.Reader) (int64, error) {
+	return pk.Tuple{
+		(*pk.Boolean)(&g.Has),
+		pk.Opt{Has: (*pk.Boolean)(&g.Has), Field: pk.Tuple{(*pk.Identifier)(&g.Dimension), &g.Pos}},
+	}.ReadFrom(r)
+}
@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/Tnze/go-mc/chat"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+func TestMetadataRoundTrip(t *testing.T) {
+	name := chat.Text("Steve")
+	values := []MetadataValue{
+		&Byte{Byte: 0x42},
+		ptr(VarInt(-5)),
+		ptr(VarLong(1 << 40)),
+		ptr(Float(1.5)),
+		ptr(String("hello")),
+		&Chat{Message: name},
+		&OptionalChat{pk.Option[chat.Message, *chat.Message]{Has: true, Val: name}},
+		&OptionalChat{},
+		&Slot{ItemID: 1, Count: 3},
+		&Slot{},
+		ptr(Boolean(true)),
+		&Rotation{1, 2, 3},
+		&Position{pk.Position{X: 1, Y: -64, Z: 300}},
+		&OptPosition{pk.Option[pk.Position, *pk.Position]{Has: true, Val: pk.Position{X: 5}}},
+		ptr(East),
+		&OptUUID{pk.Option[pk.UUID, *pk.UUID]{Has: true, Val: pk.UUID(uuid.New())}},
+		ptr(BlockState(1)),
+		ptr(OptBlockState(0)),
+		&Particle{ID: 5},
+		&VillagerData{Type: 2, Profession: 5, Level: 3},
+		&OptVarInt{Has: true, Val: 0},
+		&OptVarInt{},
+		ptr(Crouching),
+		ptr(CatVariant(3)),
+		ptr(FrogVariant(1)),
+		&OptGlobalPos{Has: true, Dimension: "minecraft:overworld", Pos: pk.Position{X: 1, Y: 2, Z: 3}},
+		&OptGlobalPos{},
+		ptr(PaintingVariant(7)),
+		ptr(SnifferState(2)),
+		&Vector3{1, 1, 1},
+		&Quaternion{0, 0, 0, 1},
+	}
+	for _, v := range values {
+		var buf bytes.Buffer
+		if _, err := v.WriteTo(&buf); err != nil {
+			t.Fatalf("%T: write: %v", v, err)
+		}
+		got := reflect.New(reflect.TypeOf(v).Elem()).Interface().(MetadataValue)
+		if _, err := got.ReadFrom(&buf); err != nil {
+			t.Fatalf("%T: read: %v", v, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%T: %d bytes left unread", v, buf.Len())
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Errorf("%T: got %v, want %v", v, got, v)
+		}
+	}
+}
+
+func TestMetadataDirty(t *testing.T) {
+	var m Metadata
+	m.SetFlag(FlagCrouching, true)
+	pose := Crouching
+	m.Set(IndexPose, &pose)
+	if set := m.TakeDirty(); len(set) != 2 || set[0].Index != IndexFlags || set[1].Index != IndexPose {
+		t.Fatalf("dirty fields: %v", set)
+	}
+	if m.Dirty() {
+		t.Fatal("fields still dirty after TakeDirty")
+	}
+
+	// Той самий прапорець ще раз - нічого не змінилось
+	m.SetFlag(FlagCrouching, true)
+	if m.Dirty() {
+		t.Error("unchanged flag marked dirty")
+	}
+	m.SetFlag(FlagGlowing, true)
+	if set := m.TakeDirty(); len(set) != 1 || m.Flags() != FlagCrouching|FlagGlowing {
+		t.Errorf("flags %#x, dirty %v", m.Flags(), set)
+	}
+	if all := m.All(); len(all) != 2 {
+		t.Errorf("all fields: %v", all)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,121 @@
+// Йоу, чат! Зараз розберемо як сервер знає, які метадані відправити!
+// Кожна сутність тримає свої метадані в Metadata.
+// Коли поле змінюється, воно позначається як змінене (dirty).
+// Раз на тік світ забирає змінені поля і відправляє їх гравцям, які бачать сутність,
+// а гравець, який тільки побачив сутність, отримує всі поля одразу.
+
+package entity
+
+import (
+	"sort"
+
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// Індекси спільних полів, які є в усіх сутностей
+const (
+	IndexFlags             byte = 0 // Byte з прапорцями Flag*
+	IndexAirSupply         byte = 1 // VarInt, скільки повітря залишилось
+	IndexCustomName        byte = 2 // OptionalChat
+	IndexCustomNameVisible byte = 3 // Boolean
+	IndexSilent            byte = 4 // Boolean
+	IndexNoGravity         byte = 5 // Boolean
+	IndexPose              byte = 6 // Pose
+	IndexFrozenTicks       byte = 7 // VarInt, скільки тіків сутність мерзне
+)
+
+// Прапорці в полі IndexFlags
+const (
+	FlagOnFire     byte = 0x01 // Горить
+	FlagCrouching  byte = 0x02 // Присів
+	FlagSprinting  byte = 0x08 // Біжить
+	FlagSwimming   byte = 0x10 // Плаває
+	FlagInvisible  byte = 0x20 // Невидимий
+	FlagGlowing    byte = 0x40 // Світиться
+	FlagFallFlying byte = 0x80 // Летить з елітрами
+)
+
+// Metadata - метадані однієї сутності зі списком змінених полів
+// Не потокобезпечна: змінювати тільки з тік-горутини
+type Metadata struct {
+	fields MetadataSet // всі поля, відсортовані за індексом
+	dirty  []byte      // індекси змінених полів
+}
+
+// find повертає позицію поля з індексом index, або місце, куди його вставити
+func (m *Metadata) find(index byte) (int, bool) {
+	i := sort.Search(len(m.fields), func(i int) bool { return m.fields[i].Index >= index })
+	return i, i < len(m.fields) && m.fields[i].Index == index
+}
+
+// Get повертає значення поля, або nil якщо поле не встановлене
+func (m *Metadata) Get(index byte) MetadataValue {
+	if i, ok := m.find(index); ok {
+		return m.fields[i].MetadataValue
+	}
+	return nil
+}
+
+// Set встановлює поле і позначає його зміненим
+func (m *Metadata) Set(index byte, v MetadataValue) {
+	i, ok := m.find(index)
+	if ok {
+		m.fields[i].MetadataValue = v
+	} else {
+		m.fields = append(m.fields, MetadataField{})
+		copy(m.fields[i+1:], m.fields[i:])
+		m.fields[i] = MetadataField{Index: index, MetadataValue: v}
+	}
+	for _, d := range m.dirty {
+		if d == index {
+			return
+		}
+	}
+	m.dirty = append(m.dirty, index)
+}
+
+// Flags повертає поле IndexFlags
+func (m *Metadata) Flags() byte {
+	if b, ok := m.Get(IndexFlags).(*Byte); ok {
+		return byte(b.Byte)
+	}
+	return 0
+}
+
+// SetFlag вмикає або вимикає прапорець в полі IndexFlags
+// Поле позначається зміненим тільки якщо прапорець справді змінився
+func (m *Metadata) SetFlag(flag byte, on bool) {
+	old := m.Flags()
+	flags := old &^ flag
+	if on {
+		flags |= flag
+	}
+	if flags != old || m.Get(IndexFlags) == nil {
+		m.Set(IndexFlags, &Byte{Byte: pk.Byte(flags)})
+	}
+}
+
+// Dirty повертає true якщо є змінені поля
+func (m *Metadata) Dirty() bool {
+	return len(m.dirty) > 0
+}
+
+// All повертає всі поля, для гравця, який тільки побачив сутність
+func (m *Metadata) All() MetadataSet {
+	return append(MetadataSet(nil), m.fields...)
+}
+
+// TakeDirty повертає змінені поля і забуває про зміни
+func (m *Metadata) TakeDirty() MetadataSet {
+	if len(m.dirty) == 0 {
+		return nil
+	}
+	sort.Slice(m.dirty, func(i, j int) bool { return m.dirty[i] < m.dirty[j] })
+	set := make(MetadataSet, 0, len(m.dirty))
+	for _, index := range m.dirty {
+		i, _ := m.find(index)
+		set = append(set, m.fields[i])
+	}
+	m.dirty = m.dirty[:0]
+	return set
+}
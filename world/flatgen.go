@@ -0,0 +1,174 @@
+// Йоу, чат! Зараз розберемо як працює плаский світ (superflat)!
+// Плаский світ - це просто стопка шарів блоків, однакова в кожному чанку.
+// Шари задаються рядком як у ванільних пресетах: "bedrock,2*dirt,grass_block"
+// означає 1 шар бедроку, 2 шари землі і 1 шар трави, знизу вгору.
+// Ідеально для лобі і мініігор, де рельєф тільки заважає.
+
+package world
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/level/biome"
+	"github.com/Tnze/go-mc/level/block"
+)
+
+// DefaultFlatLayers - шари класичного ванільного плаского світу
+const DefaultFlatLayers = "bedrock,2*dirt,grass_block"
+
+// FlatLayer - шар плаского світу
+type FlatLayer struct {
+	Block  block.StateID // блок, з якого складається шар
+	Height int           // товщина шару в блоках
+}
+
+// FlatGenerator генерує плаский світ з шарів блоків
+// Структури записуються в level.dat як у ванільному світі,
+// але сам сервер поки що їх не будує
+type FlatGenerator struct {
+	Layers     []FlatLayer // шари знизу вгору
+	Biome      biome.Type  // біом всього світу
+	Structures []string    // набори структур, наприклад "minecraft:villages"
+}
+
+// flatStructures - набори структур, які ванільний плаский світ вміє розставляти
+var flatStructures = []string{
+	"minecraft:ancient_cities",
+	"minecraft:buried_treasures",
+	"minecraft:desert_pyramids",
+	"minecraft:igloos",
+	"minecraft:jungle_temples",
+	"minecraft:mineshafts",
+	"minecraft:ocean_monuments",
+	"minecraft:ocean_ruins",
+	"minecraft:pillager_outposts",
+	"minecraft:ruined_portals",
+	"minecraft:shipwrecks",
+	"minecraft:strongholds",
+	"minecraft:swamp_huts",
+	"minecraft:villages",
+	"minecraft:woodland_mansions",
+}
+
+// newFlatGenerator створює плаский генератор з налаштувань config.toml
+// Порожні поля замінюються на ванільні значення за замовчуванням
+func newFlatGenerator(cfg GeneratorConfig) (Generator, error) {
+	layers := cfg.Layers
+	if layers == "" {
+		layers = DefaultFlatLayers
+	}
+	parsed, err := ParseFlatLayers(layers)
+	if err != nil {
+		return nil, err
+	}
+
+	g := FlatGenerator{Layers: parsed, Biome: biomeByName("minecraft:plains")}
+	if cfg.Biome != "" {
+		if err := g.Biome.UnmarshalText([]byte(withNamespace(cfg.Biome))); err != nil {
+			return nil, fmt.Errorf("unknown biome %q", cfg.Biome)
+		}
+	}
+	for _, name := range cfg.Structures {
+		name = withNamespace(name)
+		if !slices.Contains(flatStructures, name) {
+			return nil, fmt.Errorf("unknown structure set %q", name)
+		}
+		g.Structures = append(g.Structures, name)
+	}
+	return g, nil
+}
+
+// ParseFlatLayers розбирає рядок шарів у форматі ванільних пресетів
+// Кожен шар - це "блок" або "кількість*блок", шари розділені комами, знизу вгору
+func ParseFlatLayers(s string) ([]FlatLayer, error) {
+	var layers []FlatLayer
+	total := 0
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		height := 1
+		if n, name, ok := strings.Cut(part, "*"); ok {
+			var err error
+			if height, err = strconv.Atoi(strings.TrimSpace(n)); err != nil || height < 1 {
+				return nil, fmt.Errorf("invalid layer height in %q", part)
+			}
+			part = strings.TrimSpace(name)
+		}
+		b, ok := block.FromID[withNamespace(part)]
+		if !ok {
+			return nil, fmt.Errorf("unknown block %q", part)
+		}
+		total += height
+		layers = append(layers, FlatLayer{Block: block.ToStateID[b], Height: height})
+	}
+	if total > chunkHeight {
+		return nil, errors.New("flat layers are higher than the world")
+	}
+	return layers, nil
+}
+
+func (g FlatGenerator) Generate(pos [2]int32, seed int64) *level.Chunk {
+	c := level.EmptyChunk(chunkSections)
+	for i := range c.Sections {
+		c.Sections[i].Biomes = level.NewBiomesPaletteContainer(4*4*4, g.Biome)
+	}
+	y := chunkMinY
+	for _, l := range g.Layers {
+		if block.IsAir(l.Block) {
+			y += l.Height // повітря і так всюди, просто пропускаємо
+			continue
+		}
+		fillLayers(c, y, slices.Repeat([]block.StateID{l.Block}, l.Height))
+		y += l.Height
+	}
+	updateHeightMaps(c)
+	c.Status = level.StatusFull
+	return c
+}
+
+// levelSettings описує плаский світ у форматі ванільного level.dat
+func (g FlatGenerator) levelSettings() map[string]any {
+	layers := make([]map[string]any, len(g.Layers))
+	for i, l := range g.Layers {
+		layers[i] = map[string]any{
+			"block":  block.StateList[l.Block].ID(),
+			"height": int32(l.Height),
+		}
+	}
+	biomeName, _ := g.Biome.MarshalText()
+	structures := g.Structures
+	if structures == nil {
+		structures = []string{}
+	}
+	return map[string]any{
+		"type": "minecraft:flat",
+		"settings": map[string]any{
+			"biome":               string(biomeName),
+			"features":            false,
+			"lakes":               false,
+			"layers":              layers,
+			"structure_overrides": structures,
+		},
+	}
+}
+
+// withNamespace додає "minecraft:" до назви, якщо простір імен не вказано
+func withNamespace(name string) string {
+	if strings.Contains(name, ":") {
+		return name
+	}
+	return "minecraft:" + name
+}
+
+// biomeByName повертає біом за назвою, яка точно існує
+func biomeByName(name string) biome.Type {
+	var b biome.Type
+	if err := b.UnmarshalText([]byte(name)); err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,85 @@
+// Йоу, чат! Перевіряємо розбір пресетів плаского світу і запис налаштувань в level.dat.
+
+package world
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Tnze/go-mc/level/block"
+)
+
+// TestParseFlatLayers розбирає рядок шарів у форматі ванільних пресетів
+func TestParseFlatLayers(t *testing.T) {
+	layers, err := ParseFlatLayers("bedrock, 3*minecraft:stone,water")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FlatLayer{
+		{Block: block.ToStateID[block.Bedrock{}], Height: 1},
+		{Block: block.ToStateID[block.Stone{}], Height: 3},
+		{Block: block.ToStateID[block.Water{}], Height: 1},
+	}
+	if len(layers) != len(want) {
+		t.Fatalf("got %d layers, want %d", len(layers), len(want))
+	}
+	for i := range want {
+		if layers[i] != want[i] {
+			t.Errorf("layer %d is %+v, want %+v", i, layers[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"0*dirt", "x*dirt", "dirtt", "400*stone"} {
+		if _, err := ParseFlatLayers(bad); err == nil {
+			t.Errorf("%q is accepted", bad)
+		}
+	}
+}
+
+// TestFlatGenerator_water перевіряє що вода рахується в MOTION_BLOCKING, але не в OCEAN_FLOOR
+func TestFlatGenerator_water(t *testing.T) {
+	g, err := NewGenerator(GeneratorConfig{Type: "flat", Layers: "bedrock,2*sand,3*water", Biome: "ocean"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := g.Generate([2]int32{1, 2}, 0)
+	if h := c.HeightMaps.MotionBlocking.Get(0); h != 6 {
+		t.Errorf("motion blocking height is %d, want 6", h)
+	}
+	if h := c.HeightMaps.OceanFloor.Get(0); h != 3 {
+		t.Errorf("ocean floor height is %d, want 3", h)
+	}
+	if b := c.Sections[5].Biomes.Get(0); b != biomeByName("minecraft:ocean") {
+		t.Errorf("biome is %v, want ocean", b)
+	}
+}
+
+// TestFlatGenerator_levelSettings перевіряє що налаштування плаского світу записуються в level.dat
+func TestFlatGenerator_levelSettings(t *testing.T) {
+	g, err := NewGenerator(GeneratorConfig{Type: "flat", Structures: []string{"villages"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lv, err := LoadLevel("level.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lv.setOverworldGenerator(g.(levelGenerator).levelSettings())
+
+	path := filepath.Join(t.TempDir(), "level.dat")
+	if err := writeLevel(path, lv.snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadLevel(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := got.data.WorldGenSettings.Dimensions["minecraft:overworld"].Generator
+	if gen["type"] != "minecraft:flat" {
+		t.Errorf("generator type is %v", gen["type"])
+	}
+
+	if _, err := NewGenerator(GeneratorConfig{Type: "flat", Structures: []string{"castles"}}); err == nil {
+		t.Error("unknown structure set is accepted")
+	}
+}
@@ -0,0 +1,175 @@
+// Йоу, чат! Зараз розберемо як сервер створює нові чанки!
+// Коли гравець заходить туди, де ще ніхто не був, чанку немає на диску.
+// Тоді його треба згенерувати. Раніше ми просто заливали все камінням,
+// а тепер генератор - це окремий інтерфейс, який обирається в config.toml.
+// Так різні сервери можуть мати різні світи без змін в world.go.
+
+package world
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/level/block"
+)
+
+// Розміри чанку по висоті для overworld
+const (
+	chunkSections = 24                 // кількість секцій по 16 блоків
+	chunkMinY     = -64                // найнижчий блок світу
+	chunkHeight   = chunkSections * 16 // висота світу в блоках
+)
+
+// Generator створює нові чанки
+// Generate викликається з кількох воркерів одночасно, тому результат
+// має залежати тільки від позиції і сіда, а не від спільного стану
+type Generator interface {
+	Generate(pos [2]int32, seed int64) *level.Chunk
+}
+
+// GeneratorConfig - налаштування генератора з таблиці [generator] в config.toml
+// Поля крім Type потрібні не всім генераторам, решта їх ігнорує
+type GeneratorConfig struct {
+	Type       string   // назва генератора
+	Layers     string   // шари плаского світу, наприклад "bedrock,2*dirt,grass_block"
+	Biome      string   // біом плаского світу, наприклад "minecraft:plains"
+	Structures []string // структури плаского світу, наприклад "minecraft:villages"
+}
+
+// generators - всі відомі генератори за назвою з config.toml
+var generators = map[string]func(cfg GeneratorConfig) (Generator, error){
+	"void":  func(GeneratorConfig) (Generator, error) { return VoidGenerator{}, nil },
+	"flat":  newFlatGenerator,
+	"stone": func(GeneratorConfig) (Generator, error) { return StoneGenerator{}, nil },
+	"noise": func(GeneratorConfig) (Generator, error) { return &NoiseGenerator{}, nil },
+}
+
+// NewGenerator створює генератор за його назвою та налаштуваннями
+func NewGenerator(cfg GeneratorConfig) (Generator, error) {
+	newGen, ok := generators[cfg.Type]
+	if !ok {
+		names := make([]string, 0, len(generators))
+		for n := range generators {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown generator %q, available: %s", cfg.Type, strings.Join(names, ", "))
+	}
+	return newGen(cfg)
+}
+
+// levelGenerator - генератор, який вміє описати себе в level.dat
+// Так ванільний сервер чи редактор світів знатимуть, як цей світ був створений
+type levelGenerator interface {
+	levelSettings() map[string]any
+}
+
+// VoidGenerator генерує порожні чанки з повітря
+// Підходить для лобі, де все будується вручну
+type VoidGenerator struct{}
+
+func (VoidGenerator) Generate(pos [2]int32, seed int64) *level.Chunk {
+	c := level.EmptyChunk(chunkSections)
+	c.Status = level.StatusFull
+	return c
+}
+
+// StoneGenerator заливає весь чанк камінням
+// Так сервер генерував світ до появи генераторів
+type StoneGenerator struct{}
+
+func (StoneGenerator) Generate(pos [2]int32, seed int64) *level.Chunk {
+	c := level.EmptyChunk(chunkSections)
+	stone := block.ToStateID[block.Stone{}]
+	for s := range c.Sections {
+		for i := 0; i < 16*16*16; i++ {
+			c.Sections[s].SetBlock(i, stone)
+		}
+	}
+	updateHeightMaps(c)
+	c.Status = level.StatusFull
+	return c
+}
+
+// fillLayers заповнює горизонтальні шари блоків знизу вгору, починаючи з висоти y
+func fillLayers(c *level.Chunk, y int, layers []block.StateID) {
+	for _, state := range layers {
+		sec := &c.Sections[(y-chunkMinY)>>4]
+		base := sectionIndex(0, y-chunkMinY, 0)
+		for i := 0; i < 16*16; i++ {
+			sec.SetBlock(base|i, state)
+		}
+		y++
+	}
+}
+
+// sectionIndex повертає індекс блоку всередині секції
+// Блоки лежать в порядку y, z, x
+func sectionIndex(x, y, z int) int {
+	return (y&15)<<8 | z<<4 | x
+}
+
+// updateHeightMaps перераховує всі карти висот чанку
+// Клієнт використовує їх для рендеру дощу, а сервер - для спавну і фізики
+// Значення - це висота першого блоку над поверхнею, рахуючи від chunkMinY
+func updateHeightMaps(c *level.Chunk) {
+	for z := 0; z < 16; z++ {
+		for x := 0; x < 16; x++ {
+			updateHeightMapColumn(c, x, z)
+		}
+	}
+}
+
+// updateHeightMapColumn перераховує карти висот в стовпці x, z чанку
+// Викликається після кожної зміни блоку, щоб карти не застарівали
+func updateHeightMapColumn(c *level.Chunk, x, z int) {
+	var surface, motion, noLeaves, floor int
+	for y := len(c.Sections)*16 - 1; y >= 0 && (floor == 0 || noLeaves == 0); y-- {
+		sec := &c.Sections[y>>4]
+		if sec.BlockCount == 0 {
+			y &^= 15 // вся секція з повітря - пропускаємо її
+			continue
+		}
+		state := sec.GetBlock(sectionIndex(x, y, z))
+		if block.IsAir(state) {
+			continue
+		}
+		solid, fluid, leaves := blockHeightMapKind(state)
+		if surface == 0 {
+			surface = y + 1
+		}
+		if motion == 0 && (solid || fluid) {
+			motion = y + 1
+		}
+		if noLeaves == 0 && (solid || fluid) && !leaves {
+			noLeaves = y + 1
+		}
+		if solid {
+			floor = y + 1
+		}
+	}
+	hm := &c.HeightMaps
+	i := z*16 + x
+	hm.WorldSurface.Set(i, surface)
+	hm.WorldSurfaceWG.Set(i, surface)
+	hm.MotionBlocking.Set(i, motion)
+	hm.MotionBlockingNoLeaves.Set(i, noLeaves)
+	hm.OceanFloor.Set(i, floor)
+	hm.OceanFloorWG.Set(i, floor)
+}
+
+// blockHeightMapKind визначає як блок впливає на карти висот
+// solid - блок зупиняє рух, fluid - вода чи лава, leaves - листя
+func blockHeightMapKind(state block.StateID) (solid, fluid, leaves bool) {
+	switch id := block.StateList[state].ID(); {
+	case isWater(state) || id == "minecraft:lava":
+		return false, true, false
+	case !hasCollision(state):
+		return false, false, false
+	case strings.HasSuffix(id, "_leaves"):
+		return true, false, true
+	}
+	return true, false, false
+}
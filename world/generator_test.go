@@ -0,0 +1,41 @@
+// Йоу, чат! Перевіряємо що генератори будують правильні чанки і рахують карти висот.
+
+package world
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/level/block"
+)
+
+// TestFlatGenerator перевіряє шари і карти висот плаского світу
+func TestFlatGenerator(t *testing.T) {
+	g, err := NewGenerator(GeneratorConfig{Type: "flat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := g.Generate([2]int32{0, 0}, 0)
+	sec := &c.Sections[0]
+	if got := sec.GetBlock(sectionIndex(3, 3, 7)); got != block.ToStateID[block.GrassBlock{}] {
+		t.Errorf("top layer is %v, want grass block", block.StateList[got])
+	}
+	if sec.BlockCount != 4*16*16 {
+		t.Errorf("block count is %d, want %d", sec.BlockCount, 4*16*16)
+	}
+	// Трава на висоті -61, тому перший вільний блок - 4-й від дна світу
+	for i := 0; i < 16*16; i++ {
+		if h := c.HeightMaps.MotionBlocking.Get(i); h != 4 {
+			t.Fatalf("motion blocking height at %d is %d, want 4", i, h)
+		}
+	}
+}
+
+// TestNewGenerator перевіряє вибір генератора за назвою
+func TestNewGenerator(t *testing.T) {
+	if _, err := NewGenerator(GeneratorConfig{Type: "void"}); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewGenerator(GeneratorConfig{Type: "amplified"}); err == nil {
+		t.Error("unknown generator is accepted")
+	}
+}
@@ -0,0 +1,318 @@
+// Йоу, чат! Зараз розберемо чому камінь копається довше за землю!
+// У кожного блоку є міцність (hardness): земля 0.5, камінь 1.5, обсидіан 50.
+// Інструмент потрібного типу копає швидше: кирка - камінь, сокира - дерево,
+// лопата - землю. А деякі блоки без кирки потрібного рівня взагалі нічого
+// не дають і копаються в 3 рази довше.
+// В go-mc немає цих даних, тому тут таблиця для найпоширеніших блоків.
+// Невідомі блоки перевіряються як блоки з міцністю 1, щоб їх не ламали миттєво.
+// Тут же визначається, чи має блок колізію: цю перевірку ділять
+// встановлення блоків, рушій світла і карти висот.
+
+package world
+
+import (
+	"strings"
+	"sync"
+
+	"FlowyCore/world/entity"
+	"FlowyCore/world/item"
+	"github.com/Tnze/go-mc/level/block"
+	"github.com/Tnze/go-mc/nbt"
+)
+
+// toolKind - тип інструмента
+type toolKind uint8
+
+const (
+	toolNone toolKind = iota
+	toolPickaxe
+	toolAxe
+	toolShovel
+	toolHoe
+	toolSword
+)
+
+// Рівні матеріалів інструментів
+const (
+	tierWood int8 = iota
+	tierStone
+	tierIron
+	tierDiamond
+	tierNetherite
+	tierAny int8 = -1 // блок дає дроп навіть рукою
+)
+
+// blockInfo - властивості блоку для копання
+type blockInfo struct {
+	hardness float64  // міцність, -1 = блок не ламається
+	tool     toolKind // інструмент, який копає блок швидше
+	harvest  int8     // мінімальний рівень інструмента для дропу, tierAny - без інструмента
+	shears   float64  // швидкість ножиць, 0 = як рукою
+	known    bool     // блок є в таблиці
+}
+
+func soft(hardness float64) blockInfo { return blockInfo{hardness, toolNone, tierAny, 0, true} }
+func pickaxe(hardness float64, tier int8) blockInfo {
+	return blockInfo{hardness, toolPickaxe, tier, 0, true}
+}
+func axe(hardness float64) blockInfo    { return blockInfo{hardness, toolAxe, tierAny, 0, true} }
+func shovel(hardness float64) blockInfo { return blockInfo{hardness, toolShovel, tierAny, 0, true} }
+
+var unbreakable = blockInfo{-1, toolNone, tierAny, 0, true}
+
+// blockInfos - властивості блоків за назвою без minecraft:
+var blockInfos = map[string]blockInfo{
+	"bedrock": unbreakable, "barrier": unbreakable, "light": unbreakable,
+	"command_block": unbreakable, "chain_command_block": unbreakable, "repeating_command_block": unbreakable,
+	"structure_block": unbreakable, "jigsaw": unbreakable, "moving_piston": unbreakable,
+	"end_portal": unbreakable, "end_portal_frame": unbreakable, "end_gateway": unbreakable,
+	"nether_portal": unbreakable, "water": unbreakable, "lava": unbreakable,
+
+	"obsidian": pickaxe(50, tierDiamond), "crying_obsidian": pickaxe(50, tierDiamond),
+	"respawn_anchor": pickaxe(50, tierDiamond), "netherite_block": pickaxe(50, tierDiamond),
+	"ancient_debris": pickaxe(30, tierDiamond), "ender_chest": pickaxe(22.5, tierWood),
+	"enchanting_table": pickaxe(5, tierWood), "anvil": pickaxe(5, tierWood), "iron_door": pickaxe(5, tierWood), "iron_trapdoor": pickaxe(5, tierWood),
+
+	"stone": pickaxe(1.5, tierWood), "granite": pickaxe(1.5, tierWood), "diorite": pickaxe(1.5, tierWood),
+	"andesite": pickaxe(1.5, tierWood), "polished_granite": pickaxe(1.5, tierWood),
+	"polished_diorite": pickaxe(1.5, tierWood), "polished_andesite": pickaxe(1.5, tierWood),
+	"stone_bricks": pickaxe(1.5, tierWood), "smooth_stone": pickaxe(2, tierWood),
+	"cobblestone": pickaxe(2, tierWood), "mossy_cobblestone": pickaxe(2, tierWood), "bricks": pickaxe(2, tierWood),
+	"deepslate": pickaxe(3, tierWood), "cobbled_deepslate": pickaxe(3.5, tierWood),
+	"tuff": pickaxe(1.5, tierWood), "calcite": pickaxe(0.75, tierWood),
+	"sandstone": pickaxe(0.8, tierWood), "red_sandstone": pickaxe(0.8, tierWood),
+	"netherrack": pickaxe(0.4, tierWood), "end_stone": pickaxe(3, tierWood), "quartz_block": pickaxe(0.8, tierWood),
+	"furnace": pickaxe(3.5, tierWood), "terracotta": pickaxe(1.25, tierWood),
+	"ice": {0.5, toolPickaxe, tierAny, 0, true}, "packed_ice": {0.5, toolPickaxe, tierAny, 0, true},
+	"blue_ice": {2.8, toolPickaxe, tierAny, 0, true},
+
+	"coal_ore": pickaxe(3, tierWood), "deepslate_coal_ore": pickaxe(4.5, tierWood),
+	"nether_gold_ore": pickaxe(3, tierWood), "nether_quartz_ore": pickaxe(3, tierWood),
+	"iron_ore": pickaxe(3, tierStone), "deepslate_iron_ore": pickaxe(4.5, tierStone),
+	"copper_ore": pickaxe(3, tierStone), "deepslate_copper_ore": pickaxe(4.5, tierStone),
+	"lapis_ore": pickaxe(3, tierStone), "deepslate_lapis_ore": pickaxe(4.5, tierStone),
+	"gold_ore": pickaxe(3, tierIron), "deepslate_gold_ore": pickaxe(4.5, tierIron),
+	"redstone_ore": pickaxe(3, tierIron), "deepslate_redstone_ore": pickaxe(4.5, tierIron),
+	"diamond_ore": pickaxe(3, tierIron), "deepslate_diamond_ore": pickaxe(4.5, tierIron),
+	"emerald_ore": pickaxe(3, tierIron), "deepslate_emerald_ore": pickaxe(4.5, tierIron),
+	"coal_block": pickaxe(5, tierWood), "redstone_block": pickaxe(5, tierWood),
+	"iron_block": pickaxe(5, tierStone), "copper_block": pickaxe(3, tierStone), "lapis_block": pickaxe(3, tierStone),
+	"gold_block": pickaxe(3, tierIron), "diamond_block": pickaxe(5, tierIron), "emerald_block": pickaxe(5, tierIron),
+
+	"dirt": shovel(0.5), "coarse_dirt": shovel(0.5), "rooted_dirt": shovel(0.5), "podzol": shovel(0.5),
+	"grass_block": shovel(0.6), "mycelium": shovel(0.6), "farmland": shovel(0.6), "dirt_path": shovel(0.65),
+	"sand": shovel(0.5), "red_sand": shovel(0.5), "gravel": shovel(0.6), "clay": shovel(0.6), "mud": shovel(0.5),
+	"soul_sand": shovel(0.5), "soul_soil": shovel(0.5),
+	"snow": {0.1, toolShovel, tierWood, 0, true}, "snow_block": {0.2, toolShovel, tierWood, 0, true},
+
+	"crafting_table": axe(2.5), "chest": axe(2.5), "trapped_chest": axe(2.5), "barrel": axe(2.5),
+	"bookshelf": axe(1.5), "lectern": axe(2.5), "note_block": axe(0.8), "jukebox": axe(2),
+
+	"hay_block": {0.5, toolHoe, tierAny, 0, true}, "sponge": {0.6, toolHoe, tierAny, 0, true},
+	"wet_sponge": {0.6, toolHoe, tierAny, 0, true}, "target": {0.5, toolHoe, tierAny, 0, true},
+	"cobweb": {4, toolSword, tierAny, 15, true}, "glass": soft(0.3), "glass_pane": soft(0.3), "glowstone": soft(0.3), "powder_snow": soft(0.25),
+
+	"tnt": soft(0), "torch": soft(0), "wall_torch": soft(0), "redstone_wire": soft(0),
+	"grass": soft(0), "tall_grass": soft(0), "fern": soft(0), "large_fern": soft(0), "dead_bush": soft(0),
+	"dandelion": soft(0), "poppy": soft(0), "blue_orchid": soft(0), "allium": soft(0), "azure_bluet": soft(0),
+	"oxeye_daisy": soft(0), "cornflower": soft(0), "lily_of_the_valley": soft(0), "wither_rose": soft(0),
+	"sunflower": soft(0), "lilac": soft(0), "rose_bush": soft(0), "peony": soft(0),
+	"sugar_cane": soft(0), "wheat": soft(0), "carrots": soft(0), "potatoes": soft(0), "beetroots": soft(0),
+	"lily_pad": soft(0), "seagrass": soft(0), "tall_seagrass": soft(0), "kelp": soft(0), "kelp_plant": soft(0),
+}
+
+// blockSuffixes - властивості цілих груп блоків за закінченням назви
+var blockSuffixes = []struct {
+	suffix string
+	info   blockInfo
+}{
+	{"_ore", pickaxe(3, tierWood)},
+	{"_log", axe(2)}, {"_wood", axe(2)}, {"_stem", axe(2)}, {"_hyphae", axe(2)}, {"_planks", axe(2)},
+	{"_fence_gate", axe(2)}, {"_fence", axe(2)}, {"_trapdoor", axe(3)}, {"_door", axe(3)},
+	{"_sign", axe(1)},
+	{"_leaves", blockInfo{0.2, toolHoe, tierAny, 15, true}},
+	{"_wool", blockInfo{0.8, toolNone, tierAny, 5, true}},
+	{"_carpet", soft(0.1)}, {"_bed", soft(0.2)}, {"_button", soft(0.5)},
+	{"_concrete_powder", shovel(0.5)}, {"_concrete", pickaxe(1.8, tierWood)},
+	{"_glazed_terracotta", pickaxe(1.4, tierWood)}, {"_terracotta", pickaxe(1.25, tierWood)},
+	{"_glass_pane", soft(0.3)}, {"_glass", soft(0.3)},
+	{"_sapling", soft(0)}, {"_tulip", soft(0)}, {"_mushroom", soft(0)}, {"_torch", soft(0)},
+}
+
+// digInfo повертає властивості блоку для копання
+func digInfo(state block.StateID) blockInfo {
+	name := strings.TrimPrefix(block.StateList[state].ID(), "minecraft:")
+	if info, ok := lookupBlockInfo(name); ok {
+		return info
+	}
+	// Сходинки, плити і стіни копаються як матеріал, з якого зроблені
+	for _, shape := range []string{"_stairs", "_slab", "_wall"} {
+		if base, ok := strings.CutSuffix(name, shape); ok {
+			for _, material := range []string{base, base + "s", base + "_planks", base + "_block"} {
+				if info, ok := lookupBlockInfo(material); ok {
+					return info
+				}
+			}
+		}
+	}
+	return blockInfo{hardness: 1, harvest: tierAny}
+}
+
+// lookupBlockInfo шукає блок в таблиці, а потім серед груп блоків
+func lookupBlockInfo(name string) (blockInfo, bool) {
+	if info, ok := blockInfos[name]; ok {
+		return info, true
+	}
+	for _, s := range blockSuffixes {
+		if strings.HasSuffix(name, s.suffix) {
+			return s.info, true
+		}
+	}
+	return blockInfo{}, false
+}
+
+// tool - інструмент в руці гравця
+type tool struct {
+	kind  toolKind
+	tier  int8
+	speed float64 // швидкість на блоках свого типу
+}
+
+// toolTiers - рівень і швидкість інструментів за матеріалом
+var toolTiers = map[string]tool{
+	"wooden":    {tier: tierWood, speed: 2},
+	"stone":     {tier: tierStone, speed: 4},
+	"iron":      {tier: tierIron, speed: 6},
+	"diamond":   {tier: tierDiamond, speed: 8},
+	"netherite": {tier: tierNetherite, speed: 9},
+	"golden":    {tier: tierWood, speed: 12},
+}
+
+var toolKinds = map[string]toolKind{
+	"pickaxe": toolPickaxe, "axe": toolAxe, "shovel": toolShovel, "hoe": toolHoe, "sword": toolSword,
+}
+
+// toolOf повертає інструмент з предмета, для не інструментів kind == toolNone
+func toolOf(s entity.Slot) tool {
+	if s.Count <= 0 {
+		return tool{}
+	}
+	name := strings.TrimPrefix(item.Name(s.ItemID), "minecraft:")
+	material, kind, ok := strings.Cut(name, "_")
+	t, isTier := toolTiers[material]
+	k, isTool := toolKinds[kind]
+	if !ok || !isTier || !isTool {
+		return tool{}
+	}
+	t.kind = k
+	if k == toolSword {
+		t.speed = 15 // меч швидко ріже тільки павутину
+	}
+	return t
+}
+
+// isShears перевіряє, чи предмет - ножиці
+func isShears(s entity.Slot) bool {
+	return s.Count > 0 && item.Name(s.ItemID) == "minecraft:shears"
+}
+
+// canHarvest перевіряє, чи дасть блок дроп, якщо копати його цим інструментом
+func (info blockInfo) canHarvest(t tool) bool {
+	return info.harvest == tierAny || t.kind == info.tool && t.tier >= info.harvest
+}
+
+// efficiency повертає рівень чарів "Ефективність" на предметі
+func efficiency(s entity.Slot) int {
+	if s.NBT.Type != nbt.TagCompound {
+		return 0
+	}
+	var tags struct {
+		Enchantments []struct {
+			ID  string `nbt:"id"`
+			Lvl int16  `nbt:"lvl"`
+		}
+	}
+	if err := s.NBT.Unmarshal(&tags); err != nil {
+		return 0
+	}
+	for _, e := range tags.Enchantments {
+		if e.ID == "minecraft:efficiency" {
+			return int(e.Lvl)
+		}
+	}
+	return 0
+}
+
+// destroyProgress повертає, яку частину блоку гравець вкопує за тік, 1 = блок ламається одразу
+// Формула з ванільного BlockBehaviour.getDestroyProgress без зілля і чар на шоломі
+func destroyProgress(info blockInfo, held entity.Slot, onGround, inWater bool) float64 {
+	switch {
+	case info.hardness < 0:
+		return 0
+	case info.hardness == 0:
+		return 1
+	}
+	t := toolOf(held)
+	speed := 1.0
+	switch {
+	case isShears(held) && info.shears > 0:
+		speed = info.shears
+	case t.kind != toolNone && t.kind == info.tool:
+		speed = t.speed
+	}
+	if speed > 1 {
+		if lvl := efficiency(held); lvl > 0 {
+			speed += float64(lvl*lvl + 1)
+		}
+	}
+	if inWater {
+		speed /= 5
+	}
+	if !onGround {
+		speed /= 5
+	}
+	div := 100.0
+	if info.canHarvest(t) {
+		div = 30
+	}
+	return speed / info.hardness / div
+}
+
+// noCollision - закінчення назв блоків, крізь які можна пройти
+var noCollision = []string{"torch", "_sign", "_banner", "_button", "_pressure_plate", "rail", "_sapling", "_tulip"}
+
+// collisionTable - чи має колізію кожен стан блоку
+var collisionTable = sync.OnceValue(func() []bool {
+	table := make([]bool, len(block.StateList))
+	for state := range block.StateList {
+		table[state] = blockHasCollision(block.StateID(state))
+	}
+	return table
+})
+
+// hasCollision перевіряє, чи заважає блок рухатись і стояти гравцю
+// Одна перевірка для встановлення блоків, світла і карт висот
+// Рослини, факели, кнопки, таблички і тонкий сніг можна ставити навіть в гравця
+func hasCollision(state block.StateID) bool {
+	return collisionTable()[state]
+}
+
+// blockHasCollision рахує колізію блоку для collisionTable
+func blockHasCollision(state block.StateID) bool {
+	name := strings.TrimPrefix(block.StateList[state].ID(), "minecraft:")
+	switch name {
+	case "tnt", "lily_pad":
+		return true // ламаються миттєво, але стояти на них можна
+	case "snow", "cobweb", "powder_snow", "water", "lava", "bubble_column":
+		return false
+	}
+	if info := digInfo(state); info.known && info.hardness == 0 {
+		return false
+	}
+	for _, s := range noCollision {
+		if strings.HasSuffix(name, s) {
+			return false
+		}
+	}
+	return !block.IsAir(state)
+}
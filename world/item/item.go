@@ -0,0 +1,55 @@
+// Йоу, чат! Зараз розберемо як сервер знає, який предмет в тебе в руці!
+// Клієнт передає предмети числовими ID з реєстру minecraft:item.
+// Дані go-mc про предмети застарілі (1.17), тому список назв для 1.19.4
+// лежить поруч в items.txt: номер рядка - це ID предмета в протоколі.
+
+package item
+
+import (
+	_ "embed"
+	"strings"
+
+	"github.com/Tnze/go-mc/level/block"
+)
+
+//go:embed items.txt
+var itemsTxt string
+
+var (
+	names  []string         // назви предметів без minecraft: за ID
+	byName map[string]int32 // ID предметів за назвою без minecraft:
+)
+
+func init() {
+	names = strings.Fields(itemsTxt)
+	byName = make(map[string]int32, len(names))
+	for id, name := range names {
+		byName[name] = int32(id)
+	}
+}
+
+// Name повертає назву предмета, наприклад minecraft:stone
+// Для невідомого ID повертає порожній рядок
+func Name(id int32) string {
+	if id < 0 || int(id) >= len(names) {
+		return ""
+	}
+	return "minecraft:" + names[id]
+}
+
+// ByName шукає ID предмета за назвою, з minecraft: або без
+func ByName(name string) (int32, bool) {
+	id, ok := byName[strings.TrimPrefix(name, "minecraft:")]
+	return id, ok
+}
+
+// Block повертає блок, який ставить предмет
+// false - предмет не є блоком, наприклад меч чи яблуко
+func Block(id int32) (block.Block, bool) {
+	name := Name(id)
+	if name == "" || name == "minecraft:air" {
+		return nil, false
+	}
+	b, ok := block.FromID[name]
+	return b, ok
+}
@@ -0,0 +1,37 @@
+package item
+
+import "testing"
+
+func TestItemIDs(t *testing.T) {
+	// ID з реєстру minecraft:item для 1.19.4
+	for name, want := range map[string]int32{
+		"minecraft:air":             0,
+		"minecraft:stone":           1,
+		"minecraft:oak_stairs":      359,
+		"minecraft:diamond_pickaxe": 795,
+	} {
+		if id, ok := ByName(name); !ok || id != want {
+			t.Errorf("ByName(%q) = %d, %v, want %d", name, id, ok, want)
+		}
+		if got := Name(want); got != name {
+			t.Errorf("Name(%d) = %q, want %q", want, got, name)
+		}
+	}
+	if Name(-1) != "" || Name(100000) != "" {
+		t.Error("unknown IDs must have no name")
+	}
+}
+
+func TestItemBlock(t *testing.T) {
+	stairs, _ := ByName("oak_stairs")
+	if b, ok := Block(stairs); !ok || b.ID() != "minecraft:oak_stairs" {
+		t.Errorf("oak_stairs places %v", b)
+	}
+	pickaxe, _ := ByName("diamond_pickaxe")
+	if _, ok := Block(pickaxe); ok {
+		t.Error("a pickaxe is not a block")
+	}
+	if _, ok := Block(0); ok {
+		t.Error("air is not a placeable block")
+	}
+}
@@ -0,0 +1,137 @@
+// Йоу, чат! Зараз розберемо як світ пам'ятає свій стан між перезапусками!
+// Файл level.dat зберігає все, що стосується світу загалом:
+// час, погоду, правила гри, складність, точку спавну і т.д.
+// Ми завантажуємо його при старті, тримаємо в пам'яті живу копію
+// і записуємо назад при автозбереженні та при зупинці сервера.
+
+package world
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/Tnze/go-mc/nbt"
+	"github.com/Tnze/go-mc/save"
+)
+
+// Level - живий стан рівня, завантажений з level.dat
+// Поля змінюються тільки під tickLock світу, якому належить рівень
+type Level struct {
+	path string         // шлях до level.dat
+	data save.LevelData // поточний стан рівня
+}
+
+// LoadLevel читає level.dat з диску
+// Файл стиснутий gzip і містить NBT структуру save.Level
+func LoadLevel(path string) (l *Level, errRet error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func(f *os.File) {
+		err2 := f.Close()
+		if errRet == nil && err2 != nil {
+			errRet = fmt.Errorf("close level data fail: %w", err2)
+		}
+	}(f)
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("open gzip reader fail: %w", err)
+	}
+	lv, err := save.ReadLevel(r)
+	if err != nil {
+		return nil, fmt.Errorf("read level data fail: %w", err)
+	}
+	if lv.Data.GameRules == nil {
+		lv.Data.GameRules = make(map[string]string)
+	}
+	return &Level{path: path, data: lv.Data}, nil
+}
+
+// Seed повертає сід світу з WorldGenSettings
+func (l *Level) Seed() int64 {
+	return l.data.WorldGenSettings.Seed
+}
+
+// snapshot повертає копію стану рівня, яку можна записувати без блокування
+// Мапу правил гри копіюємо, бо тік-горутина може змінювати її паралельно
+func (l *Level) snapshot() save.LevelData {
+	data := l.data
+	data.GameRules = maps.Clone(l.data.GameRules)
+	return data
+}
+
+// setOverworldGenerator записує в level.dat, яким генератором створюється overworld
+func (l *Level) setOverworldGenerator(settings map[string]any) {
+	dims := l.data.WorldGenSettings.Dimensions
+	if dims == nil {
+		dims = make(map[string]save.DimensionGenerator)
+		l.data.WorldGenSettings.Dimensions = dims
+	}
+	dims["minecraft:overworld"] = save.DimensionGenerator{
+		Type:      "minecraft:overworld",
+		Generator: settings,
+	}
+}
+
+// writeLevel атомарно записує стан рівня в level.dat
+func writeLevel(path string, data save.LevelData) error {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := nbt.NewEncoder(w).Encode(save.Level{Data: data}, ""); err != nil {
+		return fmt.Errorf("encode level data fail: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("close gzip writer fail: %w", err)
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// SaveLevel записує поточний стан світу в level.dat
+// Знімок робиться під tickLock, а сам запис - вже без блокування
+func (w *World) SaveLevel() error {
+	w.tickLock.Lock()
+	w.level.data.LastPlayed = time.Now().UnixMilli()
+	data := w.level.snapshot()
+	w.tickLock.Unlock()
+	return writeLevel(w.level.path, data)
+}
+
+// GameRule повертає значення правила гри, наприклад "doDaylightCycle"
+// Другий результат false якщо правило не задане в level.dat
+func (w *World) GameRule(name string) (string, bool) {
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	v, ok := w.level.data.GameRules[name]
+	return v, ok
+}
+
+// SetGameRule змінює правило гри
+// Нове значення потрапить в level.dat при наступному збереженні
+func (w *World) SetGameRule(name, value string) {
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	w.level.data.GameRules[name] = value
+	if name == ruleDaylightCycle {
+		w.broadcastTime() // клієнти мають одразу зупинити або запустити сонце
+	}
+}
+
+// Difficulty повертає складність світу (0 - мирна, 1 - легка, 2 - нормальна, 3 - складна)
+func (w *World) Difficulty() byte {
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	return w.level.data.Difficulty
+}
+
+// SetDifficulty змінює складність світу
+func (w *World) SetDifficulty(difficulty byte) {
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	w.level.data.Difficulty = difficulty
+}
@@ -0,0 +1,40 @@
+// Йоу, чат! Перевіряємо що level.dat переживає цикл читання і запису без втрат.
+
+package world
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestLevel_RoundTrip читає level.dat з репозиторію, змінює його і записує назад
+func TestLevel_RoundTrip(t *testing.T) {
+	lv, err := LoadLevel("level.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lv.data.Time = 123456
+	lv.data.DayTime = 6000
+	lv.data.Raining = true
+	lv.data.GameRules["doDaylightCycle"] = "false"
+
+	path := filepath.Join(t.TempDir(), "level.dat")
+	if err := writeLevel(path, lv.snapshot()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadLevel(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.data.Time != 123456 || got.data.DayTime != 6000 || !got.data.Raining {
+		t.Errorf("time/weather are not preserved: %d %d %v", got.data.Time, got.data.DayTime, got.data.Raining)
+	}
+	if got.data.GameRules["doDaylightCycle"] != "false" {
+		t.Errorf("game rule is not preserved: %q", got.data.GameRules["doDaylightCycle"])
+	}
+	if got.data.SpawnX != lv.data.SpawnX || got.data.SpawnY != lv.data.SpawnY || got.data.SpawnZ != lv.data.SpawnZ {
+		t.Error("spawn position is not preserved")
+	}
+}
@@ -0,0 +1,491 @@
+// Йоу, чат! Зараз розберемо як сервер рахує світло!
+// В кожному блоці два рівні світла від 0 до 15: небесне і від блоків.
+// Небесне світло 15 падає згори прямо вниз крізь прозорі блоки, а далі
+// розтікається в сторони і втрачає щонайменше 1 рівень на кожен блок.
+// Світло блоків починається зі смолоскипів, лави, світлокаменю і т.д.
+// Непрозорі блоки (opacity 15) світло не пропускають, вода і листя гасять по 1.
+//
+// Світло зберігається в секціях чанку: 2048 байт, по пів байта на блок.
+// Весь чанк освітлює воркер одразу після завантаження, бо це довго,
+// а через межі чанків світло розтікається вже в тіку, коли чанк додається у світ.
+// Коли блок змінюється, ми спершу гасимо світло, яке від нього залежало,
+// а потім знову розтікаємо його від сусідів, які лишились світлими.
+// Змінене світло йде гравцям пакетом ClientboundLightUpdate в кінці тіку.
+
+package world
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/level/block"
+)
+
+// lightKind - вид світла
+type lightKind uint8
+
+const (
+	skyLight   lightKind = iota // світло неба
+	blockLight                  // світло від блоків
+)
+
+// lightProps - як блок впливає на світло
+type lightProps struct {
+	opacity  uint8 // скільки світла гасить блок, 15 - не пропускає зовсім
+	emission uint8 // скільки світла дає блок
+}
+
+// lightEmission - світло блоків за назвою без minecraft:
+// Блоки з властивістю lit світять тільки коли lit=true
+var lightEmission = map[string]uint8{
+	"torch": 14, "wall_torch": 14, "soul_torch": 10, "soul_wall_torch": 10,
+	"redstone_torch": 7, "redstone_wall_torch": 7, "end_rod": 14,
+	"lantern": 15, "soul_lantern": 10, "glowstone": 15, "sea_lantern": 15,
+	"jack_o_lantern": 15, "shroomlight": 15, "beacon": 15, "conduit": 15,
+	"ochre_froglight": 15, "verdant_froglight": 15, "pearlescent_froglight": 15,
+	"fire": 15, "soul_fire": 10, "lava": 15, "campfire": 15, "soul_campfire": 10,
+	"furnace": 13, "blast_furnace": 13, "smoker": 13, "redstone_lamp": 15,
+	"redstone_ore": 9, "deepslate_redstone_ore": 9,
+	"end_portal": 15, "end_gateway": 15, "nether_portal": 11, "crying_obsidian": 10,
+	"enchanting_table": 7, "ender_chest": 7, "glow_lichen": 7, "sculk_catalyst": 6,
+	"amethyst_cluster": 5, "large_amethyst_bud": 4, "medium_amethyst_bud": 2, "small_amethyst_bud": 1,
+	"magma_block": 3, "brewing_stand": 1, "brown_mushroom": 1, "dragon_egg": 1,
+	"end_portal_frame": 1, "sculk_sensor": 1,
+}
+
+// lightTranslucent - блоки, які гасять світло на 1, як вода
+var lightTranslucent = map[string]bool{
+	"water": true, "lava": true, "bubble_column": true, "ice": true, "frosted_ice": true,
+	"cobweb": true, "slime_block": true, "honey_block": true,
+}
+
+// lightOpaque - повні блоки, назви яких схожі на прозорі
+var lightOpaque = map[string]bool{"tinted_glass": true, "sea_lantern": true, "jack_o_lantern": true}
+
+// lightTransparent - закінчення назв неповних блоків, крізь які світло проходить
+var lightTransparent = []string{
+	"glass", "glass_pane", "iron_bars", "chain", "_stairs", "_slab", "_fence", "_fence_gate",
+	"_wall", "_door", "_trapdoor", "_carpet", "_bed", "chest", "lantern", "candle", "ladder",
+	"_head", "_skull", "scaffolding", "barrier", "beacon", "cactus", "cake", "hopper", "anvil",
+	"bell", "brewing_stand", "cauldron", "enchanting_table", "end_rod", "lectern", "stonecutter",
+	"grindstone", "conduit", "flower_pot", "_bud", "amethyst_cluster", "lightning_rod", "snow",
+	"campfire", "daylight_detector", "repeater", "comparator", "sea_pickle", "turtle_egg",
+	"piston_head", "moving_piston", "_coral", "_coral_fan", "_pot", "bamboo", "dragon_egg",
+}
+
+// lightTable - властивості світла для кожного стану блоку
+var lightTable = sync.OnceValue(func() []lightProps {
+	table := make([]lightProps, len(block.StateList))
+	for state, b := range block.StateList {
+		table[state] = lightPropsOf(block.StateID(state), b)
+	}
+	return table
+})
+
+// lightPropsOf рахує, як стан блоку впливає на світло
+func lightPropsOf(state block.StateID, b block.Block) lightProps {
+	name := strings.TrimPrefix(b.ID(), "minecraft:")
+	var p lightProps
+
+	p.emission = lightEmission[name]
+	if lit, ok := blockProp(b, "lit"); ok && !lit.Bool() {
+		p.emission = 0
+	}
+	switch name {
+	case "light":
+		level, _ := blockProp(b, "level")
+		p.emission = uint8(level.Int())
+	case "sea_pickle":
+		// Морські огірки світять тільки під водою, 6 за кожен
+		if wet, _ := blockProp(b, "waterlogged"); wet.Bool() {
+			pickles, _ := blockProp(b, "pickles")
+			p.emission = uint8(3 + 3*pickles.Int())
+		}
+	}
+	if count, ok := blockProp(b, "candles"); ok && p.emission == 0 {
+		if lit, _ := blockProp(b, "lit"); lit.Bool() {
+			p.emission = uint8(3 * count.Int())
+		}
+	}
+
+	switch {
+	case block.IsAir(state):
+	case lightTranslucent[name] || isWater(state) || strings.HasSuffix(name, "_leaves"):
+		p.opacity = 1
+	case lightOpaque[name]:
+		p.opacity = 15
+	case !hasCollision(state) || lightIsTransparent(name):
+		if slab, ok := slabType(state); ok && slab == block.SlabTypeDouble {
+			p.opacity = 15
+		} else if wet, _ := blockProp(b, "waterlogged"); wet.IsValid() && wet.Bool() {
+			p.opacity = 1
+		}
+	default:
+		p.opacity = 15
+	}
+	return p
+}
+
+// lightIsTransparent перевіряє назву блоку за lightTransparent
+func lightIsTransparent(name string) bool {
+	for _, s := range lightTransparent {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockProp повертає властивість блоку за тегом, як її пише ваніла
+func blockProp(b block.Block, name string) (reflect.Value, bool) {
+	v := reflect.ValueOf(b)
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("nbt") == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// lightNode - блок в черзі на поширення або гасіння світла
+type lightNode struct {
+	x, y, z int
+	level   uint8 // рівень світла, який блок мав до гасіння
+}
+
+// lightDirs - шість сусідів блоку, вниз - перший
+var lightDirs = [6][3]int{{0, -1, 0}, {0, 1, 0}, {0, 0, -1}, {0, 0, 1}, {-1, 0, 0}, {1, 0, 0}}
+
+// lightEngine поширює світло між блоками
+// Сам не знає, звідки беруться чанки: їх дає функція chunks
+type lightEngine struct {
+	chunks  func(pos [2]int32) *level.Chunk // чанк за позицією або nil, якщо його немає
+	changed map[[2]int32]uint32             // секції (біти), в яких змінилось світло, nil - не запам'ятовувати
+
+	queue   []lightNode // блоки, від яких треба поширити світло
+	removal []lightNode // блоки, від яких треба погасити світло
+
+	lastPos [2]int32     // останній чанк, щоб не шукати його для кожного блоку
+	last    *level.Chunk // nil - чанк ще не шукали або його немає
+}
+
+// section повертає секцію з блоком x, y, z, nil - за межами світу або чанку немає
+func (e *lightEngine) section(x, y, z int) (*level.Section, [2]int32) {
+	pos := [2]int32{int32(x >> 4), int32(z >> 4)}
+	if y < chunkMinY || y >= chunkMinY+chunkHeight {
+		return nil, pos
+	}
+	if e.last == nil || e.lastPos != pos {
+		e.last, e.lastPos = e.chunks(pos), pos
+	}
+	if e.last == nil || (y-chunkMinY)>>4 >= len(e.last.Sections) {
+		return nil, pos
+	}
+	return &e.last.Sections[(y-chunkMinY)>>4], pos
+}
+
+// props повертає властивості блоку x, y, z
+// Над світом - повітря, а там, де чанку немає, світло не проходить
+func (e *lightEngine) props(x, y, z int) lightProps {
+	if y >= chunkMinY+chunkHeight {
+		return lightProps{}
+	}
+	s, _ := e.section(x, y, z)
+	if s == nil {
+		return lightProps{opacity: 15}
+	}
+	return lightTable()[s.GetBlock(sectionIndex(x&15, y-chunkMinY, z&15))]
+}
+
+// get повертає рівень світла в блоці, над світом небо завжди 15
+func (e *lightEngine) get(k lightKind, x, y, z int) uint8 {
+	if y >= chunkMinY+chunkHeight {
+		if k == skyLight {
+			return 15
+		}
+		return 0
+	}
+	s, _ := e.section(x, y, z)
+	if s == nil {
+		return 0
+	}
+	data := s.BlockLight
+	if k == skyLight {
+		data = s.SkyLight
+	}
+	if data == nil {
+		return 0
+	}
+	i := sectionIndex(x&15, y-chunkMinY, z&15)
+	return data[i>>1] >> (i & 1 * 4) & 15
+}
+
+// set змінює рівень світла в блоці і запам'ятовує змінену секцію
+func (e *lightEngine) set(k lightKind, x, y, z int, v uint8) {
+	s, pos := e.section(x, y, z)
+	if s == nil {
+		return
+	}
+	data := &s.BlockLight
+	if k == skyLight {
+		data = &s.SkyLight
+	}
+	if *data == nil {
+		*data = make([]byte, 2048)
+	}
+	i := sectionIndex(x&15, y-chunkMinY, z&15)
+	shift := i & 1 * 4
+	(*data)[i>>1] = (*data)[i>>1]&^(15<<shift) | v<<shift
+	if e.changed != nil {
+		e.changed[pos] |= 1 << ((y - chunkMinY) >> 4)
+	}
+}
+
+// push ставить блок в чергу на поширення світла
+func (e *lightEngine) push(x, y, z int) {
+	e.queue = append(e.queue, lightNode{x: x, y: y, z: z})
+}
+
+// propagate розтікає світло від усіх блоків з черги
+func (e *lightEngine) propagate(k lightKind) {
+	for i := 0; i < len(e.queue); i++ {
+		n := e.queue[i]
+		l := e.get(k, n.x, n.y, n.z)
+		if l <= 1 {
+			continue
+		}
+		for d, dir := range lightDirs {
+			x, y, z := n.x+dir[0], n.y+dir[1], n.z+dir[2]
+			if y < chunkMinY || y >= chunkMinY+chunkHeight {
+				continue
+			}
+			p := e.props(x, y, z)
+			next := int(l) - max(1, int(p.opacity))
+			if k == skyLight && d == 0 && l == 15 && p.opacity == 0 {
+				next = 15 // пряме небо падає вниз без втрат
+			}
+			if next > int(e.get(k, x, y, z)) {
+				e.set(k, x, y, z, uint8(next))
+				e.push(x, y, z)
+			}
+		}
+	}
+	e.queue = e.queue[:0]
+}
+
+// unpropagate гасить світло, яке прийшло від блоків з черги removal
+// Сусідів, які світять самі по собі, ставить в чергу, щоб потім заповнити темряву
+func (e *lightEngine) unpropagate(k lightKind) {
+	for i := 0; i < len(e.removal); i++ {
+		n := e.removal[i]
+		for d, dir := range lightDirs {
+			x, y, z := n.x+dir[0], n.y+dir[1], n.z+dir[2]
+			l := e.get(k, x, y, z)
+			if l == 0 {
+				continue
+			}
+			fromHere := l < n.level || k == skyLight && d == 0 && n.level == 15 && l == 15
+			if !fromHere || y >= chunkMinY+chunkHeight {
+				e.push(x, y, z)
+				continue
+			}
+			e.set(k, x, y, z, 0)
+			e.removal = append(e.removal, lightNode{x, y, z, l})
+			if k == blockLight {
+				if em := e.props(x, y, z).emission; em > 0 {
+					e.set(k, x, y, z, em)
+					e.push(x, y, z)
+				}
+			}
+		}
+	}
+	e.removal = e.removal[:0]
+}
+
+// update перераховує світло навколо блоку, який змінився
+func (e *lightEngine) update(x, y, z int) {
+	emission := e.props(x, y, z).emission
+	for _, k := range []lightKind{skyLight, blockLight} {
+		if l := e.get(k, x, y, z); l > 0 {
+			e.set(k, x, y, z, 0)
+			e.removal = append(e.removal, lightNode{x, y, z, l})
+			e.unpropagate(k)
+		}
+		if k == blockLight && emission > 0 {
+			e.set(k, x, y, z, emission)
+			e.push(x, y, z)
+		}
+		// Якщо блок став прозорішим, світло від сусідів тепер зайде в нього
+		for _, dir := range lightDirs {
+			if e.get(k, x+dir[0], y+dir[1], z+dir[2]) > 0 {
+				e.push(x+dir[0], y+dir[1], z+dir[2])
+			}
+		}
+		e.propagate(k)
+	}
+}
+
+// lightChunk рахує світло всього чанку без урахування сусідів
+// Викликається з воркера, поки чанк ще не доданий у світ
+func lightChunk(pos [2]int32, c *level.Chunk) {
+	for i := range c.Sections {
+		c.Sections[i].SkyLight = make([]byte, 2048)
+		c.Sections[i].BlockLight = make([]byte, 2048)
+	}
+	e := &lightEngine{chunks: func(p [2]int32) *level.Chunk {
+		if p == pos {
+			return c
+		}
+		return nil
+	}}
+	x0, z0 := int(pos[0])*16, int(pos[1])*16
+	top := chunkMinY + chunkHeight
+
+	// Пряме небо: від верху світу до першого блоку, який гасить світло
+	var sky [16][16]int // найнижчий y з прямим небом в стовпці
+	for x := 0; x < 16; x++ {
+		for z := 0; z < 16; z++ {
+			y := top
+			for y > chunkMinY && e.props(x0+x, y-1, z0+z).opacity == 0 {
+				y--
+				e.set(skyLight, x0+x, y, z0+z, 15)
+			}
+			sky[x][z] = y
+		}
+	}
+	// В сторони світло тече тільки там, де сусідній стовпець нижче вже в тіні
+	for x := 0; x < 16; x++ {
+		for z := 0; z < 16; z++ {
+			shade := sky[x][z] + 1
+			for _, dir := range lightDirs[2:] {
+				if nx, nz := x+dir[0], z+dir[2]; nx >= 0 && nx < 16 && nz >= 0 && nz < 16 {
+					shade = max(shade, sky[nx][nz])
+				}
+			}
+			for y := sky[x][z]; y < min(shade, top); y++ {
+				e.push(x0+x, y, z0+z)
+			}
+		}
+	}
+	e.propagate(skyLight)
+
+	// Світло блоків починається від кожного блоку, який світить
+	for i := range c.Sections {
+		s := &c.Sections[i]
+		if s.BlockCount == 0 {
+			continue
+		}
+		for j := 0; j < 16*16*16; j++ {
+			if em := lightTable()[s.GetBlock(j)].emission; em > 0 {
+				x, y, z := x0+j&15, chunkMinY+i*16+j>>8, z0+j>>4&15
+				e.set(blockLight, x, y, z, em)
+				e.push(x, y, z)
+			}
+		}
+	}
+	e.propagate(blockLight)
+}
+
+// lightEngine повертає двигун світла для завантажених чанків світу
+// Чанки блокуються при першому зверненні, release їх відпускає
+// Викликати тільки під tickLock
+func (w *World) lightEngine() (e *lightEngine, release func()) {
+	if w.lightChanged == nil {
+		w.lightChanged = make(map[[2]int32]uint32)
+	}
+	locked := make(map[[2]int32]*LoadedChunk)
+	e = &lightEngine{
+		changed: w.lightChanged,
+		chunks: func(pos [2]int32) *level.Chunk {
+			if lc, ok := locked[pos]; ok {
+				return lc.Chunk
+			}
+			lc, ok := w.chunks[pos]
+			if !ok {
+				return nil
+			}
+			lc.Lock()
+			locked[pos] = lc
+			return lc.Chunk
+		},
+	}
+	return e, func() {
+		for _, lc := range locked {
+			lc.Unlock()
+		}
+	}
+}
+
+// joinChunkLight розтікає світло через межі нового чанку і його сусідів
+// Викликати тільки під tickLock, коли чанк вже в w.chunks
+func (w *World) joinChunkLight(pos [2]int32) {
+	e, release := w.lightEngine()
+	defer release()
+	x0, z0 := int(pos[0])*16, int(pos[1])*16
+	for _, k := range []lightKind{skyLight, blockLight} {
+		for _, side := range lightDirs[2:] {
+			if _, ok := w.chunks[[2]int32{pos[0] + int32(side[0]), pos[1] + int32(side[2])}]; !ok {
+				continue
+			}
+			for i := 0; i < 16; i++ {
+				// Блок на краю нового чанку і його сусід через межу
+				x, z := x0+i, z0+i
+				switch {
+				case side[0] < 0:
+					x = x0
+				case side[0] > 0:
+					x = x0 + 15
+				case side[2] < 0:
+					z = z0
+				default:
+					z = z0 + 15
+				}
+				nx, nz := x+side[0], z+side[2]
+				for y := chunkMinY; y < chunkMinY+chunkHeight; y++ {
+					l, nl := e.get(k, x, y, z), e.get(k, nx, y, nz)
+					if l > nl+1 {
+						e.push(x, y, z)
+					} else if nl > l+1 {
+						e.push(nx, y, nz)
+					}
+				}
+			}
+		}
+		e.propagate(k)
+	}
+}
+
+// updateLight перераховує світло навколо змінених блоків
+// Викликати тільки під tickLock
+func (w *World) updateLight(blocks [][3]int32) {
+	e, release := w.lightEngine()
+	defer release()
+	for _, pos := range blocks {
+		e.update(int(pos[0]), int(pos[1]), int(pos[2]))
+	}
+}
+
+// sendLightUpdates розсилає гравцям секції, світло в яких змінилось за тік
+// Викликати тільки під tickLock
+func (w *World) sendLightUpdates() {
+	for pos, sections := range w.lightChanged {
+		delete(w.lightChanged, pos)
+		lc, ok := w.chunks[pos]
+		if !ok {
+			continue
+		}
+		lc.Lock()
+		if lc.Chunk != nil {
+			for _, v := range lc.viewers {
+				v.ViewLightUpdate(level.ChunkPos(pos), lc.Chunk, sections)
+			}
+		}
+		lc.Unlock()
+	}
+}
@@ -0,0 +1,132 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/level/block"
+)
+
+// lightAt читає світло в блоці світу
+func lightAt(w *World, k lightKind, x, y, z int) uint8 {
+	e, release := w.lightEngine()
+	defer release()
+	return e.get(k, x, y, z)
+}
+
+func TestLightProps(t *testing.T) {
+	for _, tt := range []struct {
+		b    block.Block
+		want lightProps
+	}{
+		{block.Air{}, lightProps{}},
+		{block.Stone{}, lightProps{opacity: 15}},
+		{block.Glass{}, lightProps{}},
+		{block.Water{}, lightProps{opacity: 1}},
+		{block.OakLeaves{Distance: 7}, lightProps{opacity: 1}},
+		{block.Torch{}, lightProps{emission: 14}},
+		{block.Glowstone{}, lightProps{opacity: 15, emission: 15}},
+		{block.Furnace{Facing: block.North, Lit: true}, lightProps{opacity: 15, emission: 13}},
+		{block.Furnace{Facing: block.North}, lightProps{opacity: 15}},
+		{block.OakSlab{Type: block.SlabTypeBottom}, lightProps{}},
+		{block.OakSlab{Type: block.SlabTypeDouble}, lightProps{opacity: 15}},
+		{block.OakSlab{Type: block.SlabTypeBottom, Waterlogged: true}, lightProps{opacity: 1}},
+	} {
+		if got := lightTable()[block.ToStateID[tt.b]]; got != tt.want {
+			t.Errorf("%#v: got %+v, want %+v", tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLightChunk(t *testing.T) {
+	w, _, _ := newDigWorld()
+	lc := w.chunks[[2]int32{0, 0}]
+	lc.Sections[4].SetBlock(sectionIndex(8, 15, 8), block.ToStateID[block.Air{}]) // яма в камені
+	lc.Sections[5].SetBlock(sectionIndex(2, 4, 2), block.ToStateID[block.Glowstone{}])
+	lightChunk([2]int32{0, 0}, lc.Chunk)
+
+	for _, tt := range []struct {
+		k       lightKind
+		x, y, z int
+		want    uint8
+	}{
+		{skyLight, 3, 300, 3, 15},
+		{skyLight, 3, 16, 3, 15},
+		{skyLight, 3, 15, 3, 0}, // камінь
+		{skyLight, 8, 15, 8, 15},
+		{blockLight, 2, 20, 2, 15},
+		{blockLight, 3, 20, 2, 14},
+		{blockLight, 3, 21, 3, 12},
+		{blockLight, 2, 15, 2, 0},
+	} {
+		if got := lightAt(w, tt.k, tt.x, tt.y, tt.z); got != tt.want {
+			t.Errorf("light %d at %d %d %d: got %d, want %d", tt.k, tt.x, tt.y, tt.z, got, tt.want)
+		}
+	}
+}
+
+func TestLightUpdate(t *testing.T) {
+	w, c, _ := newDigWorld()
+	lightChunk([2]int32{0, 0}, w.chunks[[2]int32{0, 0}].Chunk)
+
+	// Смолоскип світить, а коли його прибрали - гасне
+	w.SetBlock(8, 16, 8, block.ToStateID[block.Torch{}])
+	w.subtickBlockUpdates()
+	if l := lightAt(w, blockLight, 10, 16, 8); l != 12 {
+		t.Errorf("torch light %d, want 12", l)
+	}
+	if c.lights != 1 {
+		t.Errorf("got %d light updates", c.lights)
+	}
+	w.SetBlock(8, 16, 8, block.ToStateID[block.Air{}])
+	w.subtickBlockUpdates()
+	if l := lightAt(w, blockLight, 10, 16, 8); l != 0 {
+		t.Errorf("light %d after the torch was removed", l)
+	}
+
+	// Дах закриває пряме небо, світло заходить тільки збоку
+	for x := 3; x <= 7; x++ {
+		for z := 3; z <= 7; z++ {
+			w.SetBlock(x, 20, z, block.ToStateID[block.Stone{}])
+		}
+	}
+	w.subtickBlockUpdates()
+	for _, tt := range []struct {
+		x, y, z int
+		want    uint8
+	}{
+		{5, 19, 5, 12}, {3, 19, 3, 14}, {5, 16, 5, 12}, {5, 21, 5, 15},
+	} {
+		if got := lightAt(w, skyLight, tt.x, tt.y, tt.z); got != tt.want {
+			t.Errorf("sky at %d %d %d: got %d, want %d", tt.x, tt.y, tt.z, got, tt.want)
+		}
+	}
+
+	// А коли дах зламали - знову пряме небо
+	w.SetBlock(5, 20, 5, block.ToStateID[block.Air{}])
+	w.subtickBlockUpdates()
+	if got := lightAt(w, skyLight, 5, 16, 5); got != 15 {
+		t.Errorf("sky under the hole: %d", got)
+	}
+}
+
+func TestJoinChunkLight(t *testing.T) {
+	w, _, _ := newDigWorld()
+	a := w.chunks[[2]int32{0, 0}].Chunk
+	a.Sections[5].SetBlock(sectionIndex(15, 4, 8), block.ToStateID[block.Glowstone{}])
+	lightChunk([2]int32{0, 0}, a)
+	b := level.EmptyChunk(chunkSections)
+	lightChunk([2]int32{1, 0}, b)
+	if l := lightAt(w, blockLight, 16, 20, 8); l != 0 {
+		t.Fatalf("light %d in a chunk that is not loaded", l)
+	}
+
+	w.chunks[[2]int32{1, 0}] = &LoadedChunk{Chunk: b}
+	w.joinChunkLight([2]int32{1, 0})
+	if l := lightAt(w, blockLight, 18, 20, 8); l != 12 {
+		t.Errorf("light across the border %d, want 12", l)
+	}
+	if w.lightChanged[[2]int32{1, 0}]&(1<<5) == 0 {
+		t.Error("changed section is not recorded")
+	}
+}
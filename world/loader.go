@@ -37,6 +37,7 @@ type loader struct {
 	loaded       map[[2]int32]struct{} // мапа завантажених чанків
 	loadQueue    [][2]int32            // черга чанків для завантаження
 	unloadQueue  [][2]int32            // черга чанків для вивантаження
+	sendQueue    [][2]int32            // чанки, які треба відправити гравцю в цьому підтіку
 	limiter      *rate.Limiter         // обмежувач швидкості завантаження
 }
 
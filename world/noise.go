@@ -0,0 +1,178 @@
+// Йоу, чат! Зараз розберемо звідки береться рельєф у світі!
+// Гори, долини і печери - це не магія, а шум Перліна.
+// Шум Перліна - це функція, яка для кожної точки дає плавне "випадкове" число.
+// Сусідні точки мають близькі значення, тому рельєф виходить плавним.
+// Якщо скласти кілька шарів шуму різного масштабу (октави),
+// отримаємо і великі континенти, і дрібні горбики одночасно.
+//
+// Все тут детерміноване: однаковий сід завжди дає однаковий світ.
+// Тому ми не використовуємо math/rand, а маємо власний генератор,
+// і явно округлюємо проміжні результати (float64(...)), щоб компілятор
+// не злив множення з додаванням в одну FMA інструкцію на різних процесорах.
+
+package world
+
+import "math"
+
+// random - детермінований генератор випадкових чисел (splitmix64)
+// Маленький, швидкий і однаковий на всіх платформах
+type random struct {
+	state uint64
+}
+
+// newRandom створює генератор з сіда
+func newRandom(seed int64) *random {
+	return &random{state: uint64(seed)}
+}
+
+// next повертає наступне випадкове 64-бітне число
+func (r *random) next() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	return mix64(r.state)
+}
+
+// float повертає випадкове число в діапазоні [0, 1)
+func (r *random) float() float64 {
+	return float64(r.next()>>11) / (1 << 53)
+}
+
+// mix64 перемішує біти числа (фіналізатор splitmix64)
+func mix64(z uint64) uint64 {
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// posHash повертає випадкове число для конкретного блоку
+// Використовується там, де шум не потрібен, наприклад для нерівного бедроку
+func posHash(seed int64, x, y, z int) uint64 {
+	h := mix64(uint64(seed) ^ uint64(int64(x))*0x9e3779b97f4a7c15)
+	h = mix64(h ^ uint64(int64(y))*0xc2b2ae3d27d4eb4f)
+	return mix64(h ^ uint64(int64(z))*0x165667b19e3779f9)
+}
+
+// perlin - шум Перліна (improved noise, Ken Perlin 2002)
+type perlin struct {
+	perm       [512]uint8 // перемішані числа 0..255, записані двічі щоб не рахувати модуль
+	ox, oy, oz float64    // випадковий зсув, щоб різні шуми не збігались в нулі
+}
+
+// newPerlin створює шум з випадковою таблицею перестановок
+func newPerlin(r *random) *perlin {
+	n := &perlin{
+		ox: r.float() * 256,
+		oy: r.float() * 256,
+		oz: r.float() * 256,
+	}
+	for i := 0; i < 256; i++ {
+		n.perm[i] = uint8(i)
+	}
+	// Тасування Фішера-Єйтса
+	for i := 255; i > 0; i-- {
+		j := int(r.next() % uint64(i+1))
+		n.perm[i], n.perm[j] = n.perm[j], n.perm[i]
+	}
+	copy(n.perm[256:], n.perm[:256])
+	return n
+}
+
+// sample повертає значення шуму в точці, приблизно в діапазоні [-1, 1]
+func (n *perlin) sample(x, y, z float64) float64 {
+	x, y, z = x+n.ox, y+n.oy, z+n.oz
+	fx, fy, fz := math.Floor(x), math.Floor(y), math.Floor(z)
+	xi, yi, zi := int(fx)&255, int(fy)&255, int(fz)&255
+	x, y, z = x-fx, y-fy, z-fz
+	u, v, w := fade(x), fade(y), fade(z)
+
+	p := &n.perm
+	a := int(p[xi]) + yi
+	aa, ab := int(p[a])+zi, int(p[a+1])+zi
+	b := int(p[xi+1]) + yi
+	ba, bb := int(p[b])+zi, int(p[b+1])+zi
+
+	return lerp(w,
+		lerp(v,
+			lerp(u, grad(p[aa], x, y, z), grad(p[ba], x-1, y, z)),
+			lerp(u, grad(p[ab], x, y-1, z), grad(p[bb], x-1, y-1, z))),
+		lerp(v,
+			lerp(u, grad(p[aa+1], x, y, z-1), grad(p[ba+1], x-1, y, z-1)),
+			lerp(u, grad(p[ab+1], x, y-1, z-1), grad(p[bb+1], x-1, y-1, z-1))))
+}
+
+// fade - плавна крива 6t^5 - 15t^4 + 10t^3
+func fade(t float64) float64 {
+	return float64(t*t*t) * (float64(t*float64(float64(t*6)-15)) + 10)
+}
+
+// lerp - лінійна інтерполяція між a та b
+func lerp(t, a, b float64) float64 {
+	return a + float64(t*(b-a))
+}
+
+// grad повертає скалярний добуток випадкового градієнта і вектора (x, y, z)
+// 12 напрямків на ребра куба, як в оригінальній реалізації
+func grad(hash uint8, x, y, z float64) float64 {
+	switch hash & 15 {
+	case 0, 12:
+		return x + y
+	case 1, 14:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x + z
+	case 5:
+		return -x + z
+	case 6:
+		return x - z
+	case 7:
+		return -x - z
+	case 8:
+		return y + z
+	case 9, 13:
+		return -y + z
+	case 10:
+		return y - z
+	default: // 11, 15
+		return -y - z
+	}
+}
+
+// octaveNoise - сума кількох шумів Перліна різного масштабу
+// Кожна наступна октава вдвічі дрібніша і вдвічі слабша за попередню
+type octaveNoise struct {
+	octaves []*perlin
+	norm    float64 // множник, щоб сума залишалась приблизно в [-1, 1]
+}
+
+// newOctaveNoise створює шум з вказаною кількістю октав
+func newOctaveNoise(r *random, octaves int) *octaveNoise {
+	o := &octaveNoise{octaves: make([]*perlin, octaves)}
+	amp, total := 1.0, 0.0
+	for i := range o.octaves {
+		o.octaves[i] = newPerlin(r)
+		total += amp
+		amp /= 2
+	}
+	o.norm = 1 / total
+	return o
+}
+
+// sample2 повертає значення шуму на площині
+func (o *octaveNoise) sample2(x, z float64) float64 {
+	return o.sample3(x, 0, z)
+}
+
+// sample3 повертає значення шуму в просторі
+func (o *octaveNoise) sample3(x, y, z float64) float64 {
+	var sum float64
+	freq, amp := 1.0, 1.0
+	for _, n := range o.octaves {
+		sum += float64(amp * n.sample(float64(x*freq), float64(y*freq), float64(z*freq)))
+		freq *= 2
+		amp /= 2
+	}
+	return float64(sum * o.norm)
+}
@@ -0,0 +1,332 @@
+// Йоу, чат! Зараз розберемо як гравець ставить блоки!
+// Клієнт відправляє ServerboundUseItemOn: на який блок клікнув, по якій стороні
+// і в яку точку цієї сторони. Блок ставиться поруч з клікнутим, з боку кліку,
+// а якщо клікнутий блок можна замінити (трава, вода) - на його місце.
+//
+// Стан блоку залежить від того, як гравець стоїть і куди клікнув:
+// - колоди лягають вздовж осі сторони, по якій клікнули
+// - сходинки дивляться туди ж, куди гравець, а верхні - якщо клікнули у верхню половину
+// - плити стають верхніми або нижніми, а друга така ж плита робить подвійну
+// - печі і скрині дивляться на гравця, факели і таблички на стіні - від стіни
+// Властивості стану задаються через рефлексію за тегами nbt структур go-mc,
+// тому правило для facing працює для всіх блоків з цією властивістю.
+//
+// Як і при копанні, клієнт вже поставив блок у себе, тому відмову
+// він дізнається з ClientboundBlockUpdate перед підтвердженням.
+
+package world
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+
+	"FlowyCore/world/internal/bvh"
+	"FlowyCore/world/item"
+	"github.com/Tnze/go-mc/level/block"
+)
+
+// PlaceAction - клік предметом по блоку, який чекає обробки в тіку
+type PlaceAction struct {
+	Hand     int32           // 0 - основна рука, 1 - друга
+	Pos      [3]int32        // блок, по якому клікнули
+	Face     block.Direction // сторона блоку, по якій клікнули
+	Cursor   [3]float32      // точка кліку всередині блоку, від 0 до 1
+	Sequence int32           // номер дії для ClientboundBlockChangedAck
+}
+
+// offhandSlot - слот другої руки в нумерації вікна гравця
+const offhandSlot = 45
+
+// Розміри хітбоксу гравця
+const (
+	playerWidth  = 0.6
+	playerHeight = 1.8
+)
+
+// processPlaces обробляє кліки гравця предметами по блоках з минулого тіку
+// Викликати з subtickBlockActions, коли p.Inputs заблоковані
+func (w *World) processPlaces(c Client, p *Player) {
+	if len(p.Inputs.Places) == 0 {
+		return
+	}
+	ack := p.Inputs.Places[0].Sequence
+	for _, a := range p.Inputs.Places {
+		if !w.place(p, a) {
+			// Повертаємо гравцю блоки, які він вже змінив у себе
+			// Задалеко або в незавантаженому чанку - нічого не шлемо і нічого не вантажимо
+			for _, pos := range [][3]int32{a.Pos, offset(a.Pos, a.Face)} {
+				if !canReach(p, pos) {
+					continue
+				}
+				if state, ok := w.blockAt(int(pos[0]), int(pos[1]), int(pos[2])); ok {
+					c.ViewBlockUpdate(pos, state)
+				}
+			}
+		}
+		ack = max(ack, a.Sequence)
+	}
+	p.Inputs.Places = p.Inputs.Places[:0]
+	p.blockAck = max(p.blockAck, ack) // підтвердження піде після розсилки змін в кінці тіку
+}
+
+// place ставить блок з руки гравця, false - блок не поставлено
+func (w *World) place(p *Player, a PlaceAction) bool {
+	if p.Gamemode != 0 && p.Gamemode != 1 {
+		return false // пригоди і спостерігач не ставлять блоки
+	}
+	held := p.Inputs.MainHand()
+	if a.Hand == 1 {
+		held = p.Inputs.Inventory[offhandSlot]
+	}
+	b, ok := item.Block(held.ItemID)
+	if held.Count <= 0 || !ok || !canReach(p, a.Pos) {
+		return false
+	}
+
+	// Ставимо на місце клікнутого блоку, якщо його можна замінити, інакше поруч
+	target := a.Pos
+	replaced, ok := w.blockAt(int(target[0]), int(target[1]), int(target[2]))
+	if !ok {
+		return false
+	}
+	if !canReplace(replaced, b, a, true) {
+		target = offset(a.Pos, a.Face)
+		replaced, ok = w.blockAt(int(target[0]), int(target[1]), int(target[2]))
+		if !ok || !canReplace(replaced, b, a, false) {
+			return false // за межами світу, в незавантаженому чанку або місце зайняте
+		}
+	}
+
+	state := placeState(b, replaced, a, p.rot0[0])
+	parts := map[[3]int32]block.StateID{target: state}
+	// Двері і високі квіти займають два блоки
+	if upper, ok := upperHalf(state); ok {
+		above := offset(target, block.Up)
+		if s, ok := w.blockAt(int(above[0]), int(above[1]), int(above[2])); !ok || !canReplace(s, b, a, false) {
+			return false
+		}
+		parts[above] = upper
+	}
+	for pos, s := range parts {
+		if hasCollision(s) && w.playerInside(p, pos) {
+			return false
+		}
+	}
+	for pos, s := range parts {
+		w.SetBlock(int(pos[0]), int(pos[1]), int(pos[2]), s)
+	}
+	return true
+}
+
+// offset повертає сусідній блок з боку face
+func offset(pos [3]int32, face block.Direction) [3]int32 {
+	switch face {
+	case block.Down:
+		pos[1]--
+	case block.Up:
+		pos[1]++
+	case block.North:
+		pos[2]--
+	case block.South:
+		pos[2]++
+	case block.West:
+		pos[0]--
+	case block.East:
+		pos[0]++
+	}
+	return pos
+}
+
+// canReplace перевіряє, чи можна поставити блок b на місце блоку state
+// clicked - state це блок, по якому клікнули, а не сусідній
+func canReplace(state block.StateID, b block.Block, a PlaceAction, clicked bool) bool {
+	switch s := block.StateList[state].(type) {
+	case block.Air, block.CaveAir, block.VoidAir, block.Water, block.Lava,
+		block.Grass, block.Fern, block.DeadBush, block.Seagrass, block.Vine,
+		block.GlowLichen, block.Fire, block.SoulFire, block.StructureVoid, block.Light:
+		return true
+	case block.Snow:
+		return s.Layers == 1
+	}
+	// Друга плита того ж типу робить подвійну
+	slab, ok := slabType(state)
+	if !ok || slab == block.SlabTypeDouble || block.StateList[state].ID() != b.ID() {
+		return false
+	}
+	if !clicked {
+		return true
+	}
+	upper := a.Cursor[1] > 0.5
+	horizontal := a.Face != block.Up && a.Face != block.Down
+	if slab == block.SlabTypeBottom {
+		return a.Face == block.Up || upper && horizontal
+	}
+	return a.Face == block.Down || !upper && horizontal
+}
+
+// placeState повертає стан блоку b, який гравець ставить на місце replaced
+func placeState(b block.Block, replaced block.StateID, a PlaceAction, yaw float32) block.StateID {
+	horizontal := a.Face != block.Up && a.Face != block.Down
+	// Факели, таблички, прапори і голови на стіні - окремі блоки
+	if horizontal {
+		name := b.ID()
+		i := strings.LastIndexByte(name, '_') + 1
+		if i == 0 {
+			i = len("minecraft:")
+		}
+		if wall, ok := block.FromID[name[:i]+"wall_"+name[i:]]; ok {
+			b = wall
+		}
+	}
+
+	look := lookDirection(yaw)
+	facing := opposite(look) // більшість блоків дивиться на гравця
+	name := b.ID()
+	switch {
+	case strings.HasSuffix(name, "_stairs") || strings.HasSuffix(name, "_door"):
+		facing = look
+	case horizontal && (strings.Contains(name, "wall_") || strings.HasSuffix(name, "_trapdoor")):
+		facing = a.Face
+	}
+	top := a.Face == block.Down || horizontal && a.Cursor[1] > 0.5
+	half, slab := block.Bottom, block.SlabTypeBottom
+	if top {
+		half, slab = block.Top, block.SlabTypeTop
+	}
+	if block.StateList[replaced].ID() == name {
+		slab = block.SlabTypeDouble // друга плита в тому ж блоці
+	}
+	isSource := block.StateList[replaced] == block.Block(block.Water{})
+
+	orient := func(v reflect.Value) {
+		setProp(v, "facing", facing)
+		setProp(v, "axis", axisOf(a.Face))
+		setProp(v, "half", half)
+		setProp(v, "half", block.DoubleBlockHalfLower)
+		setProp(v, "type", slab)
+		setProp(v, "rotation", block.Integer(int(math.Floor(float64(yaw+180)*16/360+0.5))&15))
+		setProp(v, "waterlogged", block.Boolean(isSource && slab != block.SlabTypeDouble))
+	}
+	// Спочатку пробуємо нульові властивості, потім перший стан блоку в реєстрі
+	v := reflect.New(reflect.TypeOf(b)).Elem()
+	orient(v)
+	if id, ok := block.ToStateID[v.Interface().(block.Block)]; ok {
+		return id
+	}
+	first := firstStates()[name]
+	v.Set(reflect.ValueOf(block.StateList[first]))
+	orient(v)
+	if id, ok := block.ToStateID[v.Interface().(block.Block)]; ok {
+		return id
+	}
+	return first
+}
+
+// upperHalf повертає верхню половину двоблочного блоку, false - блок не двоблочний
+func upperHalf(state block.StateID) (block.StateID, bool) {
+	v := reflect.New(reflect.TypeOf(block.StateList[state])).Elem()
+	v.Set(reflect.ValueOf(block.StateList[state]))
+	if !setProp(v, "half", block.DoubleBlockHalfUpper) {
+		return 0, false
+	}
+	id, ok := block.ToStateID[v.Interface().(block.Block)]
+	return id, ok
+}
+
+// setProp задає властивість стану за тегом nbt, якщо вона є і має тип значення
+func setProp(v reflect.Value, name string, value any) bool {
+	x := reflect.ValueOf(value)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Tag.Get("nbt") == name && f.Type == x.Type() {
+			v.Field(i).Set(x)
+			return true
+		}
+	}
+	return false
+}
+
+// slabType повертає тип плити, false - блок не плита
+func slabType(state block.StateID) (block.SlabType, bool) {
+	v := reflect.ValueOf(block.StateList[state])
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Tag.Get("nbt") == "type" && f.Type == reflect.TypeOf(block.SlabType(0)) {
+			return v.Field(i).Interface().(block.SlabType), true
+		}
+	}
+	return 0, false
+}
+
+// firstStates - перший стан кожного блоку в реєстрі за назвою
+var firstStates = sync.OnceValue(func() map[string]block.StateID {
+	m := make(map[string]block.StateID)
+	for id := len(block.StateList) - 1; id >= 0; id-- {
+		m[block.StateList[id].ID()] = block.StateID(id)
+	}
+	return m
+})
+
+// lookDirection повертає горизонтальний напрямок погляду гравця
+// Так само, як ванільний Direction.fromYRot
+func lookDirection(yaw float32) block.Direction {
+	dirs := [4]block.Direction{block.South, block.West, block.North, block.East}
+	return dirs[int(math.Floor(float64(yaw)/90+0.5))&3]
+}
+
+// opposite повертає протилежний напрямок
+func opposite(d block.Direction) block.Direction {
+	return [...]block.Direction{
+		block.Down: block.Up, block.Up: block.Down,
+		block.North: block.South, block.South: block.North,
+		block.West: block.East, block.East: block.West,
+	}[d]
+}
+
+// axisOf повертає вісь, вздовж якої дивиться сторона
+func axisOf(d block.Direction) block.Axis {
+	switch d {
+	case block.Down, block.Up:
+		return block.Y
+	case block.North, block.South:
+		return block.Z
+	}
+	return block.X
+}
+
+// playerInside перевіряє, чи стоїть хтось з гравців в блоці pos
+// Сам гравець p перевіряється за новою позицією, а решта - за позицією з минулого тіку
+// Читає стан гравців з інших регіонів, тому викликати тільки з subtickBlockActions
+func (w *World) playerInside(p *Player, pos [3]int32) bool {
+	box := aabb3d{
+		Lower: vec3d{float64(pos[0]), float64(pos[1]), float64(pos[2])},
+		Upper: vec3d{float64(pos[0]) + 1, float64(pos[1]) + 1, float64(pos[2]) + 1},
+	}
+	if p.Gamemode != 3 && playerBox(p.pos0).Touch(box) {
+		return true
+	}
+	inside := false
+	w.playerViews.Find(bvh.TouchPoint[vec3d, aabb3d](box.Lower),
+		func(n *playerViewNode) bool {
+			other := n.Value.Player
+			if other != p && other.Gamemode != 3 && playerBox(other.Position).Touch(box) {
+				inside = true
+				return false
+			}
+			return true
+		},
+	)
+	return inside
+}
+
+// playerBox повертає хітбокс гравця, який стоїть в точці pos
+func playerBox(pos Position) aabb3d {
+	// Трохи зменшуємо, щоб гравець впритул до блоку не заважав його поставити
+	const half, eps = playerWidth / 2, 1e-7
+	return aabb3d{
+		Lower: vec3d{pos[0] - half + eps, pos[1] + eps, pos[2] - half + eps},
+		Upper: vec3d{pos[0] + half - eps, pos[1] + playerHeight - eps, pos[2] + half - eps},
+	}
+}
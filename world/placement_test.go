@@ -0,0 +1,115 @@
+package world
+
+import (
+	"testing"
+
+	"FlowyCore/world/entity"
+	"FlowyCore/world/item"
+	"github.com/Tnze/go-mc/level/block"
+)
+
+func TestPlaceBlocks(t *testing.T) {
+	w, c, p := newDigWorld()
+	p.pos0 = Position{3.5, 16, 3.5}
+	hold := func(name string) {
+		id, _ := item.ByName(name)
+		p.Inputs.Inventory[36] = entity.Slot{ItemID: id, Count: 1}
+	}
+	var seq int32
+	place := func(a PlaceAction) {
+		t.Helper()
+		seq++ // клієнт нумерує дії з 1
+		a.Sequence = seq
+		p.Inputs.Places = []PlaceAction{a}
+		w.processPlaces(c, p)
+		w.subtickBlockUpdates()
+	}
+	at := func(x, y, z int) block.Block {
+		state, _ := w.GetBlock(x, y, z)
+		return block.StateList[state]
+	}
+
+	hold("stone")
+	place(PlaceAction{Pos: [3]int32{5, 15, 5}, Face: block.Up})
+	if at(5, 16, 5) != block.Block(block.Stone{}) || c.updates[[3]int32{5, 16, 5}] != block.ToStateID[block.Stone{}] {
+		t.Errorf("stone was not placed on top: %v", at(5, 16, 5))
+	}
+	if len(c.acks) != 1 || c.acks[0] != 1 {
+		t.Errorf("acks %v", c.acks)
+	}
+
+	// Під себе поставити не можна
+	place(PlaceAction{Pos: [3]int32{3, 15, 3}, Face: block.Up})
+	if at(3, 16, 3) != block.Block(block.Air{}) {
+		t.Error("placed a block inside the player")
+	}
+
+	// Сходинки дивляться туди ж, куди гравець (yaw 0 - на південь)
+	hold("oak_stairs")
+	place(PlaceAction{Pos: [3]int32{4, 15, 6}, Face: block.Up})
+	if want := (block.OakStairs{Facing: block.South, Half: block.Bottom}); at(4, 16, 6) != block.Block(want) {
+		t.Errorf("stairs: %#v", at(4, 16, 6))
+	}
+
+	// Колода вздовж осі сторони
+	hold("oak_log")
+	place(PlaceAction{Pos: [3]int32{4, 16, 6}, Face: block.North})
+	if want := (block.OakLog{Axis: block.Z}); at(4, 16, 5) != block.Block(want) {
+		t.Errorf("log: %#v", at(4, 16, 5))
+	}
+
+	// Дві нижні плити стають подвійною
+	hold("oak_slab")
+	place(PlaceAction{Pos: [3]int32{6, 15, 4}, Face: block.Up})
+	if want := (block.OakSlab{Type: block.SlabTypeBottom}); at(6, 16, 4) != block.Block(want) {
+		t.Errorf("slab: %#v", at(6, 16, 4))
+	}
+	place(PlaceAction{Pos: [3]int32{6, 16, 4}, Face: block.Up, Cursor: [3]float32{0.5, 0.5, 0.5}})
+	if want := (block.OakSlab{Type: block.SlabTypeDouble}); at(6, 16, 4) != block.Block(want) || at(6, 17, 4) != block.Block(block.Air{}) {
+		t.Errorf("double slab: %#v", at(6, 16, 4))
+	}
+
+	// Двері займають два блоки
+	hold("oak_door")
+	place(PlaceAction{Pos: [3]int32{6, 15, 6}, Face: block.Up})
+	lower, upper := at(6, 16, 6).(block.OakDoor), at(6, 17, 6).(block.OakDoor)
+	if lower.Half != block.DoubleBlockHalfLower || upper.Half != block.DoubleBlockHalfUpper || lower.Facing != block.South {
+		t.Errorf("door: %#v %#v", lower, upper)
+	}
+
+	// Над світом ставити нічого
+	top := chunkMinY + chunkHeight - 1
+	w.SetBlock(5, top, 5, block.ToStateID[block.Stone{}])
+	p.pos0 = Position{3.5, float64(top - 1), 3.5}
+	hold("stone")
+	place(PlaceAction{Pos: [3]int32{5, int32(top), 5}, Face: block.Up})
+	if _, ok := w.GetBlock(5, top+1, 5); ok {
+		t.Fatal("GetBlock above the world")
+	}
+	if len(c.acks) != 8 {
+		t.Errorf("got %d acks, want one per action", len(c.acks))
+	}
+}
+
+func TestPlaceState(t *testing.T) {
+	air := block.ToStateID[block.Air{}]
+	for _, tt := range []struct {
+		name string
+		b    block.Block
+		a    PlaceAction
+		yaw  float32
+		want block.Block
+	}{
+		{"wall torch", block.Torch{}, PlaceAction{Face: block.East}, 0, block.WallTorch{Facing: block.East}},
+		{"floor torch", block.Torch{}, PlaceAction{Face: block.Up}, 0, block.Torch{}},
+		{"furnace faces player", block.Furnace{}, PlaceAction{Face: block.Up}, 90, block.Furnace{Facing: block.East}},
+		{"upper stairs", block.StoneStairs{}, PlaceAction{Face: block.West, Cursor: [3]float32{0, 0.7, 0}}, 180,
+			block.StoneStairs{Facing: block.North, Half: block.Top}},
+		{"top slab", block.StoneSlab{}, PlaceAction{Face: block.Down}, 0, block.StoneSlab{Type: block.SlabTypeTop}},
+		{"sign rotation", block.OakSign{}, PlaceAction{Face: block.Up}, 0, block.OakSign{Rotation: 8}},
+	} {
+		if got := block.StateList[placeState(tt.b, air, tt.a, tt.yaw)]; got != tt.want {
+			t.Errorf("%s: got %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -24,10 +24,12 @@ package world
 import (
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 
+	"FlowyCore/world/entity"
 	pk "github.com/Tnze/go-mc/net/packet"
 	"github.com/Tnze/go-mc/yggdrasil/user"
 )
@@ -67,6 +69,10 @@ type Player struct {
 	EntitiesInView map[int32]*Entity // сутності в зоні видимості
 	view           *playerViewNode   // вузол для оптимізації видимості
 	teleport       *TeleportRequest  // запит на телепортацію
+	fallFlying     bool              // летить на елітрах
+	swimming       bool              // плаває
+	digging        *digState         // блок, який гравець зараз копає
+	blockAck       int32             // номер останньої дії з блоками, яку ще не підтвердили, 0 = немає
 
 	Inputs Inputs // поточний стан вводу від клієнта
 }
@@ -89,6 +95,39 @@ func (p *Player) getView() aabb3d {
 	}
 }
 
+// playerState - знімок полів гравця, які зберігаються в playerdata/<uuid>.dat
+// Знімок робиться під tickLock, а запис на диск відбувається вже без блокування
+type playerState struct {
+	UUID      uuid.UUID
+	Dimension string
+	Position
+	Rotation
+	OnGround
+	Gamemode int32
+	HeldItem int32  // вибраний слот хотбару
+	seq      uint64 // номер знімка, новіший знімок має більший номер
+}
+
+// playerStateSeq нумерує знімки стану гравців
+var playerStateSeq atomic.Uint64
+
+// state робить знімок стану гравця для збереження
+func (p *Player) state() playerState {
+	p.Inputs.Lock()
+	held := p.Inputs.HeldItem
+	p.Inputs.Unlock()
+	return playerState{
+		UUID:      p.UUID,
+		Dimension: "minecraft:overworld",
+		Position:  p.Position,
+		Rotation:  p.Rotation,
+		OnGround:  p.OnGround,
+		Gamemode:  p.Gamemode,
+		HeldItem:  held,
+		seq:       playerStateSeq.Add(1),
+	}
+}
+
 // TeleportRequest - запит на телепортацію гравця
 // Використовується для синхронізації позиції з клієнтом
 type TeleportRequest struct {
@@ -107,6 +146,31 @@ type Inputs struct {
 	OnGround                 // чи на землі
 	Latency    time.Duration // затримка
 	TeleportID int32         // ID останньої телепортації
+
+	Sneaking        bool  // тримає shift
+	Sprinting       bool  // біжить
+	StartFallFlying bool  // просить розкрити елітри, скидається після обробки в тіку
+	Steer           Steer // керування транспортом
+
+	Animations []Animation   // анімації, які ще не розіслані іншим гравцям
+	Digs       []DigAction   // дії з блоками, які ще не оброблені в тіку
+	Places     []PlaceAction // кліки предметами по блоках, які ще не оброблені в тіку
+
+	HeldItem  int32           // вибраний слот хотбару, 0-8
+	Inventory [46]entity.Slot // слоти інвентаря в нумерації вікна гравця, хотбар - 36-44
+}
+
+// MainHand повертає предмет в основній руці
+func (i *Inputs) MainHand() entity.Slot {
+	return i.Inventory[36+i.HeldItem]
+}
+
+// Steer - керування транспортом з ServerboundPlayerInput
+type Steer struct {
+	Sideways float32 // вліво-вправо
+	Forward  float32 // вперед-назад
+	Jump     bool    // стрибок (кінь)
+	Unmount  bool    // злізти з транспорту
 }
 
 // ClientInfo - налаштування та можливості клієнта
@@ -0,0 +1,59 @@
+// Йоу, чат! Зараз розберемо як інші гравці бачать, що ти присів чи біжиш!
+// Клієнт повідомляє серверу про shift, біг та елітри пакетом ServerboundPlayerCommand.
+// Сервер перетворює це в позу (entity.Pose) і прапорці метаданих (присів, біжить, плаває...)
+// і розсилає зміни всім, хто бачить гравця, через ClientboundSetEntityData.
+//
+// Поза обирається так само, як у ванільному Player.updatePlayerPose:
+// елітри важливіші за плавання, а плавання - за присідання.
+
+package world
+
+import "FlowyCore/world/entity"
+
+// eyeHeight - висота очей гравця, який стоїть
+const eyeHeight = 1.62
+
+// updatePose оновлює позу та прапорці гравця з його вводу
+// Викликати з тік-горутини, коли p.Inputs заблоковані
+func (w *World) updatePose(p *Player) {
+	inputs := &p.Inputs
+
+	// Елітри розкриваються тільки в повітрі і складаються при приземленні
+	if inputs.StartFallFlying {
+		inputs.StartFallFlying = false
+		if !bool(p.OnGround) && !p.swimming {
+			p.fallFlying = true
+		}
+	}
+	if p.OnGround {
+		p.fallFlying = false
+	}
+
+	// Плавати починають, коли біжать з головою під водою,
+	// а продовжують, поки біжать і тіло у воді
+	if p.swimming {
+		p.swimming = inputs.Sprinting && w.waterAt(p.pos0)
+	} else {
+		eyes := p.pos0
+		eyes[1] += eyeHeight
+		p.swimming = inputs.Sprinting && w.waterAt(eyes) && w.waterAt(p.pos0)
+	}
+
+	p.Metadata.SetFlag(entity.FlagCrouching, inputs.Sneaking)
+	p.Metadata.SetFlag(entity.FlagSprinting, inputs.Sprinting)
+	p.Metadata.SetFlag(entity.FlagSwimming, p.swimming)
+	p.Metadata.SetFlag(entity.FlagFallFlying, p.fallFlying)
+
+	pose := entity.Standing
+	switch {
+	case p.fallFlying:
+		pose = entity.FallFlying
+	case p.swimming:
+		pose = entity.Swimming
+	case inputs.Sneaking:
+		pose = entity.Crouching
+	}
+	if old, ok := p.Metadata.Get(entity.IndexPose).(*entity.Pose); !ok || *old != pose {
+		p.Metadata.Set(entity.IndexPose, &pose)
+	}
+}
@@ -0,0 +1,45 @@
+package world
+
+import (
+	"testing"
+
+	"FlowyCore/world/entity"
+)
+
+func TestUpdatePose(t *testing.T) {
+	w := &World{chunks: make(map[[2]int32]*LoadedChunk)}
+	p := &Player{}
+	pose := func() entity.Pose {
+		w.updatePose(p)
+		return *p.Metadata.Get(entity.IndexPose).(*entity.Pose)
+	}
+
+	if got := pose(); got != entity.Standing || !p.Metadata.Dirty() {
+		t.Fatalf("initial pose %v, dirty %v", got, p.Metadata.Dirty())
+	}
+	p.Metadata.TakeDirty()
+
+	p.Inputs.Sneaking = true
+	if got := pose(); got != entity.Crouching || p.Metadata.Flags() != entity.FlagCrouching {
+		t.Errorf("sneaking: pose %v, flags %#x", got, p.Metadata.Flags())
+	}
+	if set := p.Metadata.TakeDirty(); len(set) != 2 {
+		t.Errorf("sneaking changed %d fields, want flags and pose", len(set))
+	}
+
+	// Елітри в повітрі важливіші за присідання
+	p.Inputs.StartFallFlying = true
+	if got := pose(); got != entity.FallFlying || p.Inputs.StartFallFlying {
+		t.Errorf("fall flying: pose %v", got)
+	}
+	p.OnGround = true
+	if got := pose(); got != entity.Crouching {
+		t.Errorf("landed: pose %v, want crouching", got)
+	}
+
+	// На землі елітри не розкриваються
+	p.Inputs.StartFallFlying = true
+	if got := pose(); got != entity.Crouching {
+		t.Errorf("fall flying on ground: pose %v", got)
+	}
+}
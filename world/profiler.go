@@ -0,0 +1,245 @@
+// Йоу, чат! Зараз розберемо як знайти, хто гальмує сервер!
+// Кожна фаза тіку (задачі, збір чанків, завантаження чанків, гравці, сутності)
+// заміряється і записується в гістограму. Окремо заміряється кожна
+// відправка чанку гравцю і кожне читання чанку воркером.
+// Якщо тік не влазить в свої 50мс, в лог пишеться звіт: скільки зайняла
+// кожна фаза, які гравці забрали найбільше часу і який чанк був найповільнішим.
+// Гістограми можна вивести будь-коли через LogTickReport, без pprof.
+
+package world
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// tickPhase - фаза тіку
+type tickPhase int
+
+const (
+	phaseTasks         tickPhase = iota // задачі планувальника
+	phaseCollectChunks                  // забираємо чанки від воркерів
+	phaseRegions                        // зони видимості і поділ гравців на регіони
+	phaseChunkLoad                      // відправка і вивантаження чанків
+	phasePlayers                        // рух і зона видимості гравців
+	phaseEntities                       // рух сутностей
+	phaseBlocks                         // розсилка змінених блоків
+	phaseCount
+)
+
+var phaseNames = [phaseCount]string{"tasks", "collect chunks", "regions", "chunk load", "players", "entities", "blocks"}
+
+// histogramBounds - верхні межі кошиків гістограми
+// Останній кошик без межі збирає все, що довше
+var histogramBounds = [...]time.Duration{
+	100 * time.Microsecond, 250 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond,
+}
+
+// histogram - розподіл тривалостей по кошиках
+type histogram struct {
+	buckets [len(histogramBounds) + 1]uint64
+	count   uint64
+	sum     time.Duration
+	max     time.Duration
+}
+
+// add записує одну тривалість
+func (h *histogram) add(d time.Duration) {
+	i := sort.Search(len(histogramBounds), func(i int) bool { return d <= histogramBounds[i] })
+	h.buckets[i]++
+	h.count++
+	h.sum += d
+	h.max = max(h.max, d)
+}
+
+// quantile повертає верхню межу кошика, в який потрапляє квантиль q
+// Для останнього кошика повертає максимум
+func (h *histogram) quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(h.count))
+	var seen uint64
+	for i, n := range h.buckets {
+		seen += n
+		if seen > rank {
+			if i < len(histogramBounds) {
+				return min(histogramBounds[i], h.max)
+			}
+			break
+		}
+	}
+	return h.max
+}
+
+// fields повертає гістограму як поля для zap
+func (h *histogram) fields() []zap.Field {
+	var mean time.Duration
+	if h.count > 0 {
+		mean = h.sum / time.Duration(h.count)
+	}
+	return []zap.Field{
+		zap.Uint64("count", h.count),
+		zap.Duration("mean", mean),
+		zap.Duration("p50", h.quantile(0.5)),
+		zap.Duration("p95", h.quantile(0.95)),
+		zap.Duration("p99", h.quantile(0.99)),
+		zap.Duration("max", h.max),
+	}
+}
+
+// tickProfiler збирає час фаз тіку
+// Гістограми захищені м'ютексом, бо воркери і LogTickReport працюють з інших горутин
+// Дані поточного тіку чіпає тільки тік-горутина
+type tickProfiler struct {
+	mu         sync.Mutex
+	total      histogram             // весь тік
+	phases     [phaseCount]histogram // кожна фаза
+	chunkSends histogram             // відправка одного чанку гравцю
+	chunkReads histogram             // читання або генерація чанку воркером
+	slowTicks  uint64                // скільки тіків не влізли в бюджет
+
+	budget  time.Duration // скільки може тривати тік, перш ніж вважатись повільним
+	start   time.Time
+	current [phaseCount]time.Duration
+	players map[string]time.Duration // скільки часу забрав кожен гравець в цьому тіку
+	chunks  int                      // скільки чанків відправлено в цьому тіку
+	slowest struct {                 // найповільніший чанк цього тіку
+		pos    [2]int32
+		player string
+		time   time.Duration
+	}
+}
+
+// begin починає заміри нового тіку
+func (p *tickProfiler) begin() time.Time {
+	p.start = time.Now()
+	p.current = [phaseCount]time.Duration{}
+	clear(p.players)
+	p.chunks = 0
+	p.slowest.time = 0
+	return p.start
+}
+
+// phase записує фазу, яка почалася в start, і повертає час її завершення
+func (p *tickProfiler) phase(ph tickPhase, start time.Time) time.Time {
+	now := time.Now()
+	d := now.Sub(start)
+	p.current[ph] += d
+	p.mu.Lock()
+	p.phases[ph].add(d)
+	p.mu.Unlock()
+	return now
+}
+
+// player додає гравцю час, витрачений на нього в цьому тіку
+func (p *tickProfiler) player(name string, d time.Duration) {
+	if p.players == nil {
+		p.players = make(map[string]time.Duration)
+	}
+	p.players[name] += d
+}
+
+// chunkSent записує відправку чанку гравцю
+func (p *tickProfiler) chunkSent(pos [2]int32, player string, d time.Duration) {
+	p.player(player, d)
+	p.chunks++
+	if d > p.slowest.time {
+		p.slowest.pos, p.slowest.player, p.slowest.time = pos, player, d
+	}
+	p.mu.Lock()
+	p.chunkSends.add(d)
+	p.mu.Unlock()
+}
+
+// mergeRegion переносить заміри регіону в поточний тік і очищає їх
+// Регіони пишуть в свої слайси паралельно, а зливаються вже по черзі
+func (p *tickProfiler) mergeRegion(r *tickRegion) {
+	for _, t := range r.playerTimes {
+		p.player(t.name, t.time)
+	}
+	for _, s := range r.chunkSends {
+		p.chunkSent(s.pos, s.player, s.time)
+	}
+	r.playerTimes = r.playerTimes[:0]
+	r.chunkSends = r.chunkSends[:0]
+}
+
+// chunkRead записує читання чанку воркером
+// Єдиний метод, який можна викликати не з тік-горутини
+func (p *tickProfiler) chunkRead(d time.Duration) {
+	p.mu.Lock()
+	p.chunkReads.add(d)
+	p.mu.Unlock()
+}
+
+// end завершує тік і пише звіт в лог, якщо тік не вклався в бюджет
+func (p *tickProfiler) end(log *zap.Logger, n uint) {
+	total := time.Since(p.start)
+	p.mu.Lock()
+	p.total.add(total)
+	slow := p.budget > 0 && total > p.budget
+	if slow {
+		p.slowTicks++
+	}
+	p.mu.Unlock()
+	if !slow {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.Uint("tick", n),
+		zap.Duration("took", total),
+		zap.Duration("budget", p.budget),
+	}
+	for ph, d := range p.current {
+		fields = append(fields, zap.Duration(phaseNames[ph], d))
+	}
+	fields = append(fields, zap.Int("chunks sent", p.chunks))
+	if p.slowest.time > 0 {
+		fields = append(fields,
+			zap.Int32("slowest chunk x", p.slowest.pos[0]),
+			zap.Int32("slowest chunk z", p.slowest.pos[1]),
+			zap.String("slowest chunk player", p.slowest.player),
+			zap.Duration("slowest chunk", p.slowest.time))
+	}
+	if top := p.topPlayers(3); len(top) > 0 {
+		fields = append(fields, zap.Strings("top players", top))
+	}
+	log.Warn("Tick took too long", fields...)
+}
+
+// topPlayers повертає n гравців, які забрали найбільше часу в цьому тіку
+func (p *tickProfiler) topPlayers(n int) []string {
+	names := make([]string, 0, len(p.players))
+	for name := range p.players {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return p.players[names[i]] > p.players[names[j]] })
+	top := make([]string, 0, n)
+	for _, name := range names[:min(n, len(names))] {
+		top = append(top, fmt.Sprintf("%s=%s", name, p.players[name]))
+	}
+	return top
+}
+
+// LogTickReport пише в лог гістограми всіх фаз тіку з моменту запуску світу
+// Безпечно викликати з будь-якої горутини
+func (w *World) LogTickReport() {
+	p := &w.profiler
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	w.log.Info("Tick report: whole tick", append(p.total.fields(), zap.Uint64("slow", p.slowTicks))...)
+	for ph := range p.phases {
+		w.log.Info("Tick report: "+phaseNames[ph], p.phases[ph].fields()...)
+	}
+	w.log.Info("Tick report: chunk send", p.chunkSends.fields()...)
+	w.log.Info("Tick report: chunk read", p.chunkReads.fields()...)
+}
@@ -0,0 +1,78 @@
+package world
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHistogram(t *testing.T) {
+	var h histogram
+	for i := 0; i < 90; i++ {
+		h.add(200 * time.Microsecond)
+	}
+	for i := 0; i < 9; i++ {
+		h.add(7 * time.Millisecond)
+	}
+	h.add(time.Second)
+
+	if got := h.quantile(0.5); got != 250*time.Microsecond {
+		t.Errorf("p50 = %v, want 250µs", got)
+	}
+	if got := h.quantile(0.95); got != 10*time.Millisecond {
+		t.Errorf("p95 = %v, want 10ms", got)
+	}
+	if got := h.quantile(0.999); got != time.Second {
+		t.Errorf("p99.9 = %v, want max", got)
+	}
+	if h.count != 100 || h.max != time.Second {
+		t.Errorf("count %d, max %v", h.count, h.max)
+	}
+}
+
+// Повільний тік має потрапити в лог з розбивкою по фазах, гравцях і чанках
+func TestTickProfiler_slowTick(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	log := zap.New(core)
+	p := tickProfiler{budget: time.Hour}
+
+	// Швидкий тік нічого не пише
+	t0 := p.begin()
+	p.phase(phasePlayers, t0)
+	p.end(log, 1)
+	if logs.Len() != 0 {
+		t.Fatalf("fast tick logged: %v", logs.All())
+	}
+
+	p.budget = time.Nanosecond
+	t0 = p.begin()
+	p.chunkSent([2]int32{3, -4}, "Steve", 30*time.Millisecond)
+	p.chunkSent([2]int32{5, 6}, "Alex", 10*time.Millisecond)
+	p.player("Alex", 5*time.Millisecond)
+	p.player("Notch", time.Millisecond)
+	time.Sleep(time.Millisecond)
+	p.phase(phaseChunkLoad, t0)
+	p.end(log, 2)
+
+	entries := logs.TakeAll()
+	if len(entries) != 1 {
+		t.Fatalf("want one slow tick report, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["slowest chunk x"] != int32(3) || fields["slowest chunk z"] != int32(-4) || fields["slowest chunk player"] != "Steve" {
+		t.Errorf("wrong slowest chunk: %v", fields)
+	}
+	top, _ := fields["top players"].([]any)
+	if len(top) != 3 || top[0] != "Steve=30ms" || top[1] != "Alex=15ms" || top[2] != "Notch=1ms" {
+		t.Errorf("top players = %v", fields["top players"])
+	}
+	if fields["chunk load"].(time.Duration) < time.Millisecond || fields["chunks sent"] != int64(2) {
+		t.Errorf("wrong phase breakdown: %v", fields)
+	}
+	if p.slowTicks != 1 || p.total.count != 2 || p.chunkSends.count != 2 {
+		t.Errorf("slow %d, ticks %d, chunk sends %d", p.slowTicks, p.total.count, p.chunkSends.count)
+	}
+}
@@ -22,17 +22,21 @@
 package world
 
 import (
+	"bytes"
 	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/google/uuid"
-	"golang.org/x/time/rate"
 
 	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/nbt"
 	"github.com/Tnze/go-mc/save"
 	"github.com/Tnze/go-mc/save/region"
 	"github.com/Tnze/go-mc/yggdrasil/user"
@@ -41,38 +45,53 @@ import (
 // ChunkProvider реалізує систему збереження чанків
 // Використовує .mca файли для зберігання даних світу
 type ChunkProvider struct {
-	dir     string        // директорія з регіонами
-	limiter *rate.Limiter // обмежувач швидкості завантаження
+	dir     string       // директорія з регіонами
+	regions *regionCache // кеш відкритих регіонів для читання і запису
 }
 
 // NewProvider створює новий провайдер чанків
 // dir - шлях до директорії з регіонами
-// limiter - обмежувач швидкості завантаження
-func NewProvider(dir string, limiter *rate.Limiter) ChunkProvider {
-	return ChunkProvider{dir: dir, limiter: limiter}
+// cache - налаштування кешу відкритих регіонів
+// Після використання провайдер треба закрити через Close
+func NewProvider(dir string, cache RegionCacheConfig) *ChunkProvider {
+	return &ChunkProvider{dir: dir, regions: newRegionCache(cache)}
 }
 
-// ErrReachRateLimit повертається коли перевищено ліміт завантаження
-var ErrReachRateLimit = errors.New("reach rate limit")
-
 // GetChunk завантажує чанк за його координатами
-// Спочатку перевіряє ліміт, потім шукає потрібний регіон
-// і завантажує з нього дані чанку
+// Шукає потрібний регіон і завантажує з нього дані чанку
+// Безпечно викликати з кількох горутин одночасно
+// Скільки чанків вантажити і як швидко - вирішує пул завантаження світу
 func (p *ChunkProvider) GetChunk(pos [2]int32) (c *level.Chunk, errRet error) {
-	if !p.limiter.Allow() {
-		return nil, ErrReachRateLimit
+	data, err := p.readChunkData(pos)
+	if err != nil {
+		return nil, err
+	}
+
+	// Парсимо NBT дані чанку
+	var chunk save.Chunk
+	if err := chunk.Load(data); err != nil {
+		return nil, fmt.Errorf("parse chunk data fail: %w", err)
 	}
+
+	// Конвертуємо в структуру level.Chunk
+	c, err = level.ChunkFromSave(&chunk)
+	if err != nil {
+		return nil, fmt.Errorf("load chunk data fail: %w", err)
+	}
+	return c, nil
+}
+
+// readChunkData читає стиснуті NBT байти чанку з кешованого регіону
+// Розпаковка і парсинг відбуваються вже без блокування кешу
+func (p *ChunkProvider) readChunkData(pos [2]int32) ([]byte, error) {
+	p.regions.mu.Lock()
+	defer p.regions.mu.Unlock()
+
 	// Отримуємо регіон, в якому знаходиться чанк
 	r, err := p.getRegion(region.At(int(pos[0]), int(pos[1])))
 	if err != nil {
 		return nil, fmt.Errorf("open region fail: %w", err)
 	}
-	defer func(r *region.Region) {
-		err2 := r.Close()
-		if errRet == nil && err2 != nil {
-			errRet = fmt.Errorf("close region fail: %w", err2)
-		}
-	}(r)
 
 	// Перевіряємо чи існує чанк в регіоні
 	x, z := region.In(int(pos[0]), int(pos[1]))
@@ -85,83 +104,139 @@ func (p *ChunkProvider) GetChunk(pos [2]int32) (c *level.Chunk, errRet error) {
 	if err != nil {
 		return nil, fmt.Errorf("read sector fail: %w", err)
 	}
+	return data, nil
+}
 
-	// Парсимо NBT дані чанку
-	var chunk save.Chunk
-	if err := chunk.Load(data); err != nil {
-		return nil, fmt.Errorf("parse chunk data fail: %w", err)
-	}
+// HasChunk повертає true якщо чанк вже записаний в регіон
+// Файли регіонів не розпаковуються, перевіряється тільки заголовок
+func (p *ChunkProvider) HasChunk(pos [2]int32) (bool, error) {
+	p.regions.mu.Lock()
+	defer p.regions.mu.Unlock()
 
-	// Конвертуємо в структуру level.Chunk
-	c, err = level.ChunkFromSave(&chunk)
+	r, err := p.getRegion(region.At(int(pos[0]), int(pos[1])))
 	if err != nil {
-		return nil, fmt.Errorf("load chunk data fail: %w", err)
+		return false, fmt.Errorf("open region fail: %w", err)
 	}
-	return c, nil
+	x, z := region.In(int(pos[0]), int(pos[1]))
+	return r.ExistSector(x, z), nil
 }
 
-// getRegion повертає об'єкт регіону за координатами
-// Якщо файл не існує - створює новий
-func (p *ChunkProvider) getRegion(rx, rz int) (*region.Region, error) {
-	filename := fmt.Sprintf("r.%d.%d.mca", rx, rz)
-	path := filepath.Join(p.dir, filename)
-	r, err := region.Open(path)
-	if errors.Is(err, fs.ErrNotExist) {
-		r, err = region.Create(path)
-	}
-	return r, err
+// getRegion повертає регіон за координатами з кешу
+// Якщо файл не існує - повертає порожній регіон, який з'явиться на диску при першому записі
+// Викликати тільки під p.regions.mu
+func (p *ChunkProvider) getRegion(rx, rz int) (*regionFile, error) {
+	return p.regions.get([2]int{rx, rz}, p.regionPath(rx, rz))
 }
 
 // PutChunk зберігає чанк у файл регіону
-// Наразі функція закоментована, але вона конвертує чанк в NBT
-// та зберігає його у відповідний .mca файл
+// Конвертує чанк в NBT формат save.Chunk і записує його в кешований регіон
+// На диск регіон потрапляє атомарно при Flush, витісненні з кешу або Close,
+// тому падіння серверу ніколи не залишить зіпсований .mca файл
 func (p *ChunkProvider) PutChunk(pos [2]int32, c *level.Chunk) (err error) {
-	// Закоментований код збереження чанку
+	// Кодуємо чанк до того як чіпати файл регіону
+	data, err := encodeChunk(pos, c)
+	if err != nil {
+		return fmt.Errorf("encode chunk fail: %w", err)
+	}
+
+	p.regions.mu.Lock()
+	defer p.regions.mu.Unlock()
+
+	r, err := p.getRegion(region.At(int(pos[0]), int(pos[1])))
+	if err != nil {
+		return fmt.Errorf("open region fail: %w", err)
+	}
+
+	// Записуємо сектор чанку і позначаємо регіон зміненим
+	x, z := region.In(int(pos[0]), int(pos[1]))
+	if err := r.WriteSector(x, z, data); err != nil {
+		return fmt.Errorf("write sector fail: %w", err)
+	}
+	r.dirty = true
 	return nil
 }
 
+// Flush атомарно записує на диск всі змінені регіони з кешу
+func (p *ChunkProvider) Flush() error {
+	p.regions.mu.Lock()
+	defer p.regions.mu.Unlock()
+	return p.regions.flush()
+}
+
+// Close зберігає всі змінені регіони і закриває кеш
+func (p *ChunkProvider) Close() error {
+	return p.regions.close()
+}
+
+// regionPath повертає шлях до файлу регіону r.X.Z.mca
+func (p *ChunkProvider) regionPath(rx, rz int) string {
+	return filepath.Join(p.dir, fmt.Sprintf("r.%d.%d.mca", rx, rz))
+}
+
+// chunkDataVersion - версія формату даних чанку (1.19.4)
+const chunkDataVersion = 3337
+
+// Порожні NBT значення для полів save.Chunk, які ми поки не підтримуємо
+// NBT енкодер не вміє записувати порожній nbt.RawMessage, тому задаємо їх явно
+var (
+	emptyNBTList     = nbt.RawMessage{Type: nbt.TagList, Data: []byte{nbt.TagEnd, 0, 0, 0, 0}}
+	emptyNBTCompound = nbt.RawMessage{Type: nbt.TagCompound, Data: []byte{nbt.TagEnd}}
+)
+
+// encodeChunk перетворює level.Chunk у стиснуті zlib байти формату save.Chunk
+// Перший байт - тип стиснення (2 = zlib), як в ванільному майнкрафті
+func encodeChunk(pos [2]int32, c *level.Chunk) ([]byte, error) {
+	chunk := save.Chunk{
+		DataVersion:    chunkDataVersion,
+		XPos:           pos[0],
+		YPos:           -4, // найнижча секція світу (y = -64)
+		ZPos:           pos[1],
+		Heightmaps:     make(map[string][]uint64),
+		BlockTicks:     emptyNBTList,
+		FluidTicks:     emptyNBTList,
+		PostProcessing: emptyNBTList,
+		Structures:     emptyNBTCompound,
+	}
+	if err := level.ChunkToSave(c, &chunk); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(2)
+	w := zlib.NewWriter(&buf)
+	if err := nbt.NewEncoder(w).Encode(chunk, ""); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // errChunkNotExist повертається коли чанк не знайдено
 var errChunkNotExist = errors.New("ErrChunkNotExist")
 
 // PlayerProvider відповідає за збереження даних гравців
+// Одного гравця можуть одночасно зберігати автозбереження і вихід з гри,
+// тому записи йдуть по черзі, а старіший знімок не перезаписує новіший
 type PlayerProvider struct {
-	dir string // директорія з файлами гравців
+	dir    string               // директорія з файлами гравців
+	saveMu sync.Mutex           // один запис файлу гравця за раз
+	saved  map[uuid.UUID]uint64 // номер останнього записаного знімка кожного гравця
 }
 
 // NewPlayerProvider створює новий провайдер даних гравців
-func NewPlayerProvider(dir string) PlayerProvider {
-	return PlayerProvider{dir: dir}
+func NewPlayerProvider(dir string) *PlayerProvider {
+	return &PlayerProvider{dir: dir, saved: make(map[uuid.UUID]uint64)}
 }
 
 // GetPlayer завантажує дані гравця з файлу
 // Дані зберігаються в .dat файлі в форматі NBT з GZIP стисненням
 func (p *PlayerProvider) GetPlayer(name string, id uuid.UUID, pubKey *user.PublicKey, properties []user.Property) (player *Player, errRet error) {
-	// Відкриваємо файл гравця за його UUID
-	f, err := os.Open(filepath.Join(p.dir, id.String()+".dat"))
+	data, err := p.readPlayerData(id)
 	if err != nil {
 		return nil, err
 	}
-	defer func(f *os.File) {
-		err2 := f.Close()
-		if errRet == nil && err2 != nil {
-			errRet = fmt.Errorf("close player data fail: %w", err2)
-		}
-	}(f)
-
-	// Розпаковуємо GZIP
-	r, err := gzip.NewReader(f)
-	if err != nil {
-		return nil, fmt.Errorf("open gzip reader fail: %w", err)
-	}
-
-	// Читаємо NBT дані
-	data, err := save.ReadPlayerData(r)
-	if err != nil {
-		return nil, fmt.Errorf("read player data fail: %w", err)
-	}
-	if err := r.Close(); err != nil {
-		return nil, fmt.Errorf("close gzip reader fail: %w", err)
-	}
 
 	// Створюємо об'єкт гравця з завантажених даних
 	player = &Player{
@@ -169,6 +244,7 @@ func (p *PlayerProvider) GetPlayer(name string, id uuid.UUID, pubKey *user.Publi
 			EntityID: NewEntityID(),
 			Position: data.Pos,
 			Rotation: data.Rotation,
+			OnGround: data.OnGround != 0,
 		},
 		Name:       name,
 		UUID:       id,
@@ -185,3 +261,140 @@ func (p *PlayerProvider) GetPlayer(name string, id uuid.UUID, pubKey *user.Publi
 	}
 	return
 }
+
+// playerPath повертає шлях до файлу гравця playerdata/<uuid>.dat
+func (p *PlayerProvider) playerPath(id uuid.UUID) string {
+	return filepath.Join(p.dir, id.String()+".dat")
+}
+
+// readPlayerData читає і розпаковує .dat файл гравця
+func (p *PlayerProvider) readPlayerData(id uuid.UUID) (data save.PlayerData, errRet error) {
+	// Відкриваємо файл гравця за його UUID
+	f, err := os.Open(p.playerPath(id))
+	if err != nil {
+		return data, err
+	}
+	defer func(f *os.File) {
+		err2 := f.Close()
+		if errRet == nil && err2 != nil {
+			errRet = fmt.Errorf("close player data fail: %w", err2)
+		}
+	}(f)
+
+	// Розпаковуємо GZIP
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return data, fmt.Errorf("open gzip reader fail: %w", err)
+	}
+
+	// Читаємо NBT дані
+	data, err = save.ReadPlayerData(r)
+	if err != nil {
+		return data, fmt.Errorf("read player data fail: %w", err)
+	}
+	if err := r.Close(); err != nil {
+		return data, fmt.Errorf("close gzip reader fail: %w", err)
+	}
+	return data, nil
+}
+
+// SavePlayer зберігає гравця в playerdata/<uuid>.dat
+// Викликайте тільки коли гравця ніхто не змінює: після видалення зі світу
+// або з тік-горутини. Для автозбереження є World.SavePlayers
+func (p *PlayerProvider) SavePlayer(player *Player) error {
+	return p.savePlayerState(player.state())
+}
+
+// savePlayerState записує стан гравця у ванільному форматі save.PlayerData
+// Поля, яких ми ще не підтримуємо (інвентар, досвід...), беремо зі старого файлу,
+// щоб не стерти їх при збереженні
+// Знімок, старіший за вже записаний, пропускається
+func (p *PlayerProvider) savePlayerState(s playerState) error {
+	p.saveMu.Lock()
+	defer p.saveMu.Unlock()
+	if s.seq <= p.saved[s.UUID] {
+		return nil // гравець вже збережений новішим знімком, наприклад при виході
+	}
+
+	data, err := p.readPlayerData(s.UUID)
+	if errors.Is(err, fs.ErrNotExist) {
+		data = newPlayerData()
+	} else if err != nil {
+		return err
+	}
+
+	data.DataVersion = chunkDataVersion
+	data.Dimension = s.Dimension
+	data.Pos = s.Position
+	data.Rotation = s.Rotation
+	data.OnGround = 0
+	if s.OnGround {
+		data.OnGround = 1
+	}
+	data.PlayerGameType = s.Gamemode
+	setAbilities(&data, s.Gamemode)
+	data.SelectedItemSlot = s.HeldItem
+	// UUID в NBT зберігається як 4 числа int32 (big-endian)
+	for i := range data.UUID {
+		data.UUID[i] = int32(binary.BigEndian.Uint32(s.UUID[i*4:]))
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := nbt.NewEncoder(w).Encode(data, ""); err != nil {
+		return fmt.Errorf("encode player data fail: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("close gzip writer fail: %w", err)
+	}
+	if err := writeFileAtomic(p.playerPath(s.UUID), buf.Bytes()); err != nil {
+		return err
+	}
+	p.saved[s.UUID] = s.seq
+	return nil
+}
+
+// newPlayerData повертає дані нового гравця зі значеннями за замовчуванням як у ванілі
+func newPlayerData() save.PlayerData {
+	data := save.PlayerData{
+		Health:    20,
+		FoodLevel: 20,
+		Air:       300,
+	}
+	data.Abilities.WalkSpeed = 0.1
+	data.Abilities.FlySpeed = 0.05
+	data.Attributes = []struct {
+		Base float64
+		Name string
+	}{
+		{20, "minecraft:generic.max_health"},
+		{0.10000000149011612, "minecraft:generic.movement_speed"},
+		{1, "minecraft:generic.attack_damage"},
+		{4, "minecraft:generic.attack_speed"},
+	}
+	return data
+}
+
+// setAbilities виставляє можливості гравця для режиму гри, як це робить ваніль
+// Режими: 0 - виживання, 1 - творчий, 2 - пригоди, 3 - спостерігач
+func setAbilities(data *save.PlayerData, gamemode int32) {
+	a := &data.Abilities
+	creative, spectator := gamemode == 1, gamemode == 3
+	a.MayFly = boolByte(creative || spectator)
+	a.InstantBuild = boolByte(creative)
+	a.Invulnerable = boolByte(creative || spectator)
+	a.MayBuild = boolByte(gamemode == 0 || creative)
+	if spectator {
+		a.Flying = 1 // спостерігач завжди літає
+	} else if a.MayFly == 0 {
+		a.Flying = 0
+	}
+}
+
+// boolByte перетворює bool в байт для NBT
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,185 @@
+// Йоу, чат! Тут ми перевіряємо що чанки правильно зберігаються в .mca файли
+// і що після перезапуску ми прочитаємо рівно те, що записали.
+
+package world
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/level/block"
+)
+
+// TestChunkProvider_PutChunk записує чанк і читає його назад
+func TestChunkProvider_PutChunk(t *testing.T) {
+	dir := t.TempDir()
+	p := NewProvider(dir, RegionCacheConfig{MaxOpen: 4})
+	defer p.Close()
+
+	// Чанк з шаром каменю в самому низу
+	c := level.EmptyChunk(24)
+	stone := block.ToStateID[block.Stone{}]
+	for i := 0; i < 16*16; i++ {
+		c.Sections[0].SetBlock(i, stone)
+	}
+	c.Status = level.StatusFull
+
+	// Від'ємні координати щоб перевірити розрахунок регіону
+	pos := [2]int32{-3, 40}
+	if _, err := p.GetChunk(pos); !errors.Is(err, errChunkNotExist) {
+		t.Fatalf("expect errChunkNotExist, got %v", err)
+	}
+	if err := p.PutChunk(pos, c); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := p.GetChunk(pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Sections) != len(c.Sections) {
+		t.Fatalf("sections: want %d, got %d", len(c.Sections), len(got.Sections))
+	}
+	if got.Sections[0].BlockCount != 16*16 {
+		t.Errorf("block count: want %d, got %d", 16*16, got.Sections[0].BlockCount)
+	}
+	if got.Sections[0].GetBlock(0) != stone || !block.IsAir(got.Sections[0].GetBlock(16*16)) {
+		t.Error("block states are not preserved")
+	}
+	if got.Status != level.StatusFull {
+		t.Errorf("status: want %q, got %q", level.StatusFull, got.Status)
+	}
+
+	// Після атомарного запису не повинно залишатися тимчасових файлів
+	if err := p.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "r.-1.1.mca" {
+			t.Errorf("unexpected file in region dir: %s", e.Name())
+		}
+	}
+}
+
+// TestChunkProvider_regionEviction перевіряє що витіснений з кешу регіон
+// зберігається на диск і читається назад новим провайдером
+func TestChunkProvider_regionEviction(t *testing.T) {
+	dir := t.TempDir()
+	p := NewProvider(dir, RegionCacheConfig{MaxOpen: 1})
+	defer p.Close()
+
+	c := level.EmptyChunk(24)
+	c.Status = level.StatusFull
+	// Чанки з двох різних регіонів: другий витіснить перший з кешу
+	if err := p.PutChunk([2]int32{0, 0}, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.PutChunk([2]int32{32, 0}, c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "r.0.0.mca")); err != nil {
+		t.Fatalf("evicted region is not saved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "r.1.0.mca")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("cached region should not be saved before flush: %v", err)
+	}
+
+	// Close записує все, що залишилось в кеші
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	p2 := NewProvider(dir, RegionCacheConfig{MaxOpen: 1})
+	defer p2.Close()
+	for _, pos := range [][2]int32{{0, 0}, {32, 0}} {
+		if _, err := p2.GetChunk(pos); err != nil {
+			t.Errorf("chunk %v: %v", pos, err)
+		}
+	}
+}
+
+// TestPlayerProvider_SavePlayer зберігає гравця і завантажує його назад
+func TestPlayerProvider_SavePlayer(t *testing.T) {
+	p := NewPlayerProvider(t.TempDir())
+	id := uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+
+	if _, err := p.GetPlayer("Notch", id, nil, nil); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expect os.ErrNotExist, got %v", err)
+	}
+
+	player := &Player{
+		Entity: Entity{
+			Position: Position{12.5, 70, -3.25},
+			Rotation: Rotation{90, -15},
+			OnGround: true,
+		},
+		UUID:     id,
+		Gamemode: 2,
+	}
+	if err := p.SavePlayer(player); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := p.GetPlayer("Notch", id, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Position != player.Position || got.Rotation != player.Rotation || got.OnGround != player.OnGround {
+		t.Errorf("entity: want %v %v %v, got %v %v %v",
+			player.Position, player.Rotation, player.OnGround,
+			got.Position, got.Rotation, got.OnGround)
+	}
+	if got.Gamemode != player.Gamemode {
+		t.Errorf("gamemode: want %d, got %d", player.Gamemode, got.Gamemode)
+	}
+
+	// UUID всередині файлу має збігатися з іменем файлу
+	data, err := p.readPlayerData(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.UUID != [4]int32{0x069a79f4, 0x44e94726, -0x5a410357, 0x0e38aaf5} {
+		t.Errorf("uuid: got %x", data.UUID)
+	}
+}
+
+// TestPlayerProvider_SaveOrder перевіряє, що старий знімок автозбереження
+// не перезаписує новіший, збережений при виході гравця
+func TestPlayerProvider_SaveOrder(t *testing.T) {
+	p := NewPlayerProvider(t.TempDir())
+	player := &Player{UUID: uuid.New(), Gamemode: 1}
+	player.Inputs.HeldItem = 4
+
+	autosave := player.state()
+	player.Position = Position{1, 2, 3}
+	if err := p.SavePlayer(player); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.savePlayerState(autosave); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := p.readPlayerData(player.UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Pos != player.Position {
+		t.Errorf("stale snapshot overwrote the quit save: pos %v", data.Pos)
+	}
+	// Новий гравець отримує ванільні значення, а творчий режим - політ
+	a := data.Abilities
+	if a.WalkSpeed != 0.1 || a.FlySpeed != 0.05 || a.MayFly != 1 || a.InstantBuild != 1 || len(data.Attributes) == 0 {
+		t.Errorf("new player defaults: %+v, attributes %v", a, data.Attributes)
+	}
+	if data.SelectedItemSlot != 4 {
+		t.Errorf("selected slot: got %d, want 4", data.SelectedItemSlot)
+	}
+}
@@ -0,0 +1,158 @@
+// Йоу, чат! Зараз розберемо як ми кешуємо файли регіонів!
+// Раніше для кожного чанку ми відкривали .mca файл, читали заголовок і закривали.
+// При view distance 10 це сотні відкриттів файлів на кожного гравця.
+// Тепер відкриті регіони живуть в LRU кеші: недавно використані тримаємо,
+// найстаріші та ті, що давно не використовувались, закриваємо.
+
+package world
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RegionCacheConfig - налаштування кешу відкритих регіонів
+type RegionCacheConfig struct {
+	MaxOpen     int           // скільки регіонів тримати відкритими одночасно
+	IdleTimeout time.Duration // через скільки часу без звернень закривати регіон (0 = ніколи)
+}
+
+// regionCache - LRU кеш регіонів, спільний для читання і запису чанків
+// Всі операції з регіонами виконуються під mu, бо region.Region не потокобезпечний
+type regionCache struct {
+	mu    sync.Mutex
+	cfg   RegionCacheConfig
+	lru   *list.List               // спереду - найновіші, ззаду - найстаріші
+	items map[[2]int]*list.Element // координати регіону -> елемент списку
+	stop  chan struct{}            // закривається в close() щоб зупинити evictLoop
+}
+
+// cachedRegion - елемент кешу
+type cachedRegion struct {
+	pos      [2]int    // координати регіону
+	lastUsed time.Time // час останнього звернення
+	*regionFile
+}
+
+// newRegionCache створює кеш і запускає фонове закриття неактивних регіонів
+func newRegionCache(cfg RegionCacheConfig) *regionCache {
+	if cfg.MaxOpen < 1 {
+		cfg.MaxOpen = 1
+	}
+	c := &regionCache{
+		cfg:   cfg,
+		lru:   list.New(),
+		items: make(map[[2]int]*list.Element),
+		stop:  make(chan struct{}),
+	}
+	if cfg.IdleTimeout > 0 {
+		go c.evictLoop()
+	}
+	return c
+}
+
+// get повертає регіон з кешу або завантажує його з диску
+// Викликати тільки під c.mu
+func (c *regionCache) get(pos [2]int, path string) (*regionFile, error) {
+	if e, ok := c.items[pos]; ok {
+		c.lru.MoveToFront(e)
+		cr := e.Value.(*cachedRegion)
+		cr.lastUsed = time.Now()
+		return cr.regionFile, nil
+	}
+
+	r, err := loadRegionFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c.items[pos] = c.lru.PushFront(&cachedRegion{pos: pos, lastUsed: time.Now(), regionFile: r})
+
+	// Кеш переповнений - викидаємо найстаріші регіони
+	// Щойно відкритий регіон (він спереду) не чіпаємо, бо його зараз використовують
+	for e := c.lru.Back(); e != c.lru.Front() && c.lru.Len() > c.cfg.MaxOpen; {
+		prev := e.Prev()
+		// Якщо регіон не вдалося зберегти, він залишиться в кеші щоб не втратити зміни
+		_ = c.evict(e)
+		e = prev
+	}
+	return r, nil
+}
+
+// evict зберігає регіон якщо він змінений і видаляє його з кешу
+// Викликати тільки під c.mu
+func (c *regionCache) evict(e *list.Element) error {
+	cr := e.Value.(*cachedRegion)
+	if err := cr.flush(); err != nil {
+		return err
+	}
+	c.lru.Remove(e)
+	delete(c.items, cr.pos)
+	return nil
+}
+
+// flush записує на диск всі змінені регіони
+// Викликати тільки під c.mu
+func (c *regionCache) flush() error {
+	var errs []error
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		cr := e.Value.(*cachedRegion)
+		if err := cr.flush(); err != nil {
+			errs = append(errs, fmt.Errorf("flush region %d.%d fail: %w", cr.pos[0], cr.pos[1], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// evictIdle закриває регіони, до яких давно не зверталися
+// Викликати тільки під c.mu
+func (c *regionCache) evictIdle(now time.Time) error {
+	var errs []error
+	for e := c.lru.Back(); e != nil; {
+		prev := e.Prev()
+		cr := e.Value.(*cachedRegion)
+		if now.Sub(cr.lastUsed) < c.cfg.IdleTimeout {
+			break // далі в списку тільки новіші регіони
+		}
+		if err := c.evict(e); err != nil {
+			errs = append(errs, err)
+		}
+		e = prev
+	}
+	return errors.Join(errs...)
+}
+
+// evictLoop періодично прибирає неактивні регіони, поки кеш не закрито
+func (c *regionCache) evictLoop() {
+	ticker := time.NewTicker(max(c.cfg.IdleTimeout/2, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case now := <-ticker.C:
+			c.mu.Lock()
+			// Помилку ігноруємо: змінені регіони залишаться в кеші і збережуться пізніше
+			_ = c.evictIdle(now)
+			c.mu.Unlock()
+		}
+	}
+}
+
+// close зберігає всі регіони, очищує кеш і зупиняє evictLoop
+func (c *regionCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.stop:
+		return nil // вже закрито
+	default:
+		close(c.stop)
+	}
+	err := c.flush()
+	c.lru.Init()
+	clear(c.items)
+	return err
+}
@@ -0,0 +1,172 @@
+// Йоу, чат! Зараз розберемо як ми безпечно записуємо файли регіонів!
+// Якщо писати .mca файл прямо на диск і сервер впаде посеред запису,
+// файл буде зіпсований і гравці побачать дірки у світі.
+// Тому ми завантажуємо регіон в пам'ять, змінюємо його там,
+// а потім атомарно замінюємо старий файл новим.
+
+package world
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Tnze/go-mc/save/region"
+)
+
+// memFile - файл в пам'яті, який реалізує io.ReadWriteSeeker та io.WriterAt
+// region.Load приймає будь-який io.ReadWriteSeeker, тому ми можемо
+// підсунути йому байти з пам'яті замість справжнього файлу
+type memFile struct {
+	data []byte // вміст файлу
+	off  int64  // поточна позиція читання/запису
+}
+
+// Read читає дані з поточної позиції
+func (m *memFile) Read(p []byte) (n int, err error) {
+	if m.off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n = copy(p, m.data[m.off:])
+	m.off += int64(n)
+	return n, nil
+}
+
+// Write записує дані в поточну позицію, розширюючи файл за потреби
+func (m *memFile) Write(p []byte) (n int, err error) {
+	n, err = m.WriteAt(p, m.off)
+	m.off += int64(n)
+	return
+}
+
+// WriteAt записує дані за вказаним зміщенням
+// region.Region використовує його для оновлення заголовку
+func (m *memFile) WriteAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New("memFile: negative offset")
+	}
+	if end := off + int64(len(p)); end > int64(len(m.data)) {
+		m.data = append(m.data, make([]byte, end-int64(len(m.data)))...)
+	}
+	return copy(m.data[off:], p), nil
+}
+
+// Seek змінює поточну позицію
+func (m *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += m.off
+	case io.SeekEnd:
+		offset += int64(len(m.data))
+	default:
+		return 0, errors.New("memFile: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("memFile: negative position")
+	}
+	m.off = offset
+	return offset, nil
+}
+
+// regionFile - регіон, повністю завантажений в пам'ять
+// Всі зміни відбуваються в пам'яті, а на диск потрапляють тільки через save()
+type regionFile struct {
+	*region.Region
+	path  string   // шлях до .mca файлу
+	mem   *memFile // вміст файлу
+	dirty bool     // чи є зміни, яких ще немає на диску
+}
+
+// loadRegionFile читає .mca файл в пам'ять
+// Якщо файлу ще немає - створює порожній регіон (тільки заголовок)
+func loadRegionFile(path string) (*regionFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	mem := &memFile{data: data}
+	var r *region.Region
+	if len(data) == 0 {
+		r, err = region.CreateWriter(mem)
+	} else {
+		r, err = region.Load(mem)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &regionFile{Region: r, path: path, mem: mem}, nil
+}
+
+// save атомарно записує регіон на диск
+// Майнкрафт вимагає щоб розмір файлу був кратний 4096 байтам
+func (r *regionFile) save() error {
+	if err := r.PadToFullSector(); err != nil {
+		return err
+	}
+	return writeFileAtomic(r.path, r.mem.data)
+}
+
+// flush зберігає регіон на диск, але тільки якщо в ньому є зміни
+func (r *regionFile) flush() error {
+	if !r.dirty {
+		return nil
+	}
+	if err := r.save(); err != nil {
+		return err
+	}
+	r.dirty = false
+	return nil
+}
+
+// writeFileAtomic записує файл так, щоб він ніколи не залишився наполовину записаним:
+// 1. Пишемо дані в тимчасовий файл поруч з оригіналом
+// 2. Скидаємо його на диск (fsync)
+// 3. Перейменовуємо поверх оригіналу - це атомарна операція
+// Якщо сервер впаде на будь-якому кроці, на диску залишиться або старий, або новий файл
+func writeFileAtomic(path string, data []byte) (errRet error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := f.Name()
+	defer func() {
+		// Прибираємо тимчасовий файл якщо щось пішло не так
+		if errRet != nil {
+			_ = os.Remove(tmpName)
+		}
+	}()
+
+	// CreateTemp створює файл з правами 0600, а нам потрібні звичайні права
+	if err := f.Chmod(0o644); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+
+	// Синхронізуємо директорію щоб перейменування теж потрапило на диск
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+// Йоу, чат! Зараз розберемо як один світ може тікати на кількох ядрах!
+// Гравці на різних кінцях світу ніяк не впливають один на одного:
+// вони не бачать один одного і не бачать чужих чанків.
+// Тому ми ділимо гравців на регіони - групи, де хтось бачить когось.
+// Гравець A і гравець B в одному регіоні, якщо зона видимості одного
+// з них містить іншого (і так по ланцюжку: A бачить B, B бачить C).
+// Інші сутності потрапляють в регіон гравців, які їх бачать, і зливають
+// ці регіони, якщо сутність бачать гравці з різних регіонів.
+// Кожен тік регіони будуються заново, тому коли гравці сходяться,
+// регіони зливаються, а коли розходяться - діляться.
+//
+// Всередині регіону все виконується по черзі, а різні регіони - паралельно.
+// Регіон змінює тільки своїх гравців, їх списки видимих сутностей і чанки,
+// які відправляються цим гравцям. Все спільне (дерево зон видимості,
+// мапа чанків, пул завантаження, задачі планувальника) змінюється тільки
+// в послідовних частинах тіку, поки регіони не працюють.
+// Тому код, якому треба зачепити кілька регіонів, має йти через RunNextTick.
+
+package world
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"FlowyCore/world/internal/bvh"
+)
+
+// tickRegion - група гравців, яку можна тікати незалежно від інших
+type tickRegion struct {
+	clients  []Client
+	entities []*GenericEntity
+
+	// Заміри профайлера, які зливаються в нього після паралельної фази
+	playerTimes []regionPlayerTime
+	chunkSends  []regionChunkSend
+}
+
+type regionPlayerTime struct {
+	name string
+	time time.Duration
+}
+
+type regionChunkSend struct {
+	pos    [2]int32
+	player string
+	time   time.Duration
+}
+
+// playerTime записує час, витрачений на гравця
+func (r *tickRegion) playerTime(name string, d time.Duration) {
+	r.playerTimes = append(r.playerTimes, regionPlayerTime{name, d})
+}
+
+// chunkSent записує відправку чанку гравцю
+func (r *tickRegion) chunkSent(pos [2]int32, player string, d time.Duration) {
+	r.chunkSends = append(r.chunkSends, regionChunkSend{pos, player, d})
+}
+
+// buildRegions ділить гравців на регіони
+// Якщо паралельні тіки вимкнені, всі гравці потрапляють в один регіон
+// Викликати тільки під tickLock, після оновлення зон видимості
+func (w *World) buildRegions() []tickRegion {
+	clients := make([]Client, 0, len(w.players))
+	for c := range w.players {
+		clients = append(clients, c)
+	}
+	entities := make([]*GenericEntity, 0, len(w.entities))
+	for _, e := range w.entities {
+		entities = append(entities, e)
+	}
+	if w.config.RegionThreads <= 1 {
+		return []tickRegion{{clients: clients, entities: entities}}
+	}
+
+	// Об'єднуємо гравців, зона видимості яких містить іншого гравця.
+	// Це той самий пошук, яким subtickUpdateEntities шукає глядачів,
+	// тому регіон ніколи не відправить пакет гравцю з іншого регіону
+	index := make(map[*Player]int, len(clients))
+	for i, c := range clients {
+		index[w.players[c]] = i
+	}
+	parent := make([]int, len(clients))
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for i, c := range clients {
+		cond := bvh.TouchPoint[vec3d, aabb3d](vec3d(w.players[c].Position))
+		w.playerViews.Find(cond, func(n *playerViewNode) bool {
+			if j, ok := index[n.Value.Player]; ok {
+				parent[find(i)] = find(j)
+			}
+			return true
+		})
+	}
+
+	// Сутність об'єднує всіх гравців, які її бачать, і йде в їх регіон
+	owner := make([]int, len(entities)) // гравець, з регіоном якого тікається сутність, або -1
+	for k, e := range entities {
+		owner[k] = -1
+		cond := bvh.TouchPoint[vec3d, aabb3d](vec3d(e.Position))
+		w.playerViews.Find(cond, func(n *playerViewNode) bool {
+			if j, ok := index[n.Value.Player]; ok {
+				if owner[k] < 0 {
+					owner[k] = j
+				} else {
+					parent[find(owner[k])] = find(j)
+				}
+			}
+			return true
+		})
+	}
+
+	groups := make(map[int]int) // корінь -> індекс регіону
+	var regions []tickRegion
+	region := func(i int) *tickRegion {
+		root := find(i)
+		r, ok := groups[root]
+		if !ok {
+			r = len(regions)
+			groups[root] = r
+			regions = append(regions, tickRegion{})
+		}
+		return &regions[r]
+	}
+	for i, c := range clients {
+		r := region(i)
+		r.clients = append(r.clients, c)
+	}
+	// Сутності, яких ніхто не бачить, ні з ким не взаємодіють, тому тікаються в будь-якому регіоні
+	var unseen []*GenericEntity
+	for k, e := range entities {
+		if owner[k] < 0 {
+			unseen = append(unseen, e)
+			continue
+		}
+		r := region(owner[k])
+		r.entities = append(r.entities, e)
+	}
+	if len(unseen) > 0 {
+		if len(regions) == 0 {
+			regions = append(regions, tickRegion{})
+		}
+		regions[0].entities = append(regions[0].entities, unseen...)
+	}
+	// Великі регіони першими, щоб вони не залишились на кінець і не затримали тік
+	sort.Slice(regions, func(i, j int) bool {
+		return len(regions[i].clients)+len(regions[i].entities) > len(regions[j].clients)+len(regions[j].entities)
+	})
+
+	if len(regions) != w.regionCount {
+		w.log.Debug("Tick regions changed", zap.Int("from", w.regionCount), zap.Int("to", len(regions)))
+		w.regionCount = len(regions)
+	}
+	return regions
+}
+
+// runRegions виконує f для кожного регіону, паралельно в Config.RegionThreads горутинах
+// Повертається, коли всі регіони закінчили, і зливає їх заміри в профайлер
+func (w *World) runRegions(regions []tickRegion, f func(r *tickRegion)) {
+	threads := min(w.config.RegionThreads, len(regions))
+	if threads <= 1 {
+		for i := range regions {
+			f(&regions[i])
+		}
+	} else {
+		jobs := make(chan *tickRegion)
+		var wg sync.WaitGroup
+		for i := 0; i < threads; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for r := range jobs {
+					f(r)
+				}
+			}()
+		}
+		for i := range regions {
+			jobs <- &regions[i]
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	for i := range regions {
+		w.profiler.mergeRegion(&regions[i])
+	}
+}
@@ -0,0 +1,56 @@
+package world
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestBuildRegions(t *testing.T) {
+	w := &World{
+		log:     zap.NewNop(),
+		config:  Config{RegionThreads: 4},
+		players: make(map[Client]*Player),
+	}
+	add := func(x float64) (Client, *Player) {
+		c := &fakeClient{}
+		p := &Player{Entity: Entity{Position: [3]float64{x, 64, 0}}, ViewDistance: 2}
+		p.view = w.playerViews.Insert(p.getView(), playerView{c, p})
+		w.players[c] = p
+		return c, p
+	}
+	a, _ := add(0)
+	add(20)
+	c, pc := add(1000)
+
+	regions := w.buildRegions()
+	if len(regions) != 2 || len(regions[0].clients) != 2 || regions[1].clients[0] != c {
+		t.Fatalf("got %d regions %v, want [a b] [c]", len(regions), regions)
+	}
+
+	// Гравець підійшов - регіони злились
+	pc.Position = [3]float64{40, 64, 0}
+	pc.view = w.playerViews.Insert(pc.getView(), w.playerViews.Delete(pc.view))
+	if regions = w.buildRegions(); len(regions) != 1 || len(regions[0].clients) != 3 {
+		t.Fatalf("got %d regions, want a single merged one", len(regions))
+	}
+
+	// Кожен регіон виконується рівно раз, а заміри потрапляють в профайлер
+	w.players[a].Position = [3]float64{-1000, 64, 0}
+	w.players[a].view = w.playerViews.Insert(w.players[a].getView(), w.playerViews.Delete(w.players[a].view))
+	regions = w.buildRegions()
+	if len(regions) != 2 {
+		t.Fatalf("got %d regions, want a split into [b c] [a]", len(regions))
+	}
+	var runs atomic.Int32
+	w.runRegions(regions, func(r *tickRegion) {
+		runs.Add(1)
+		for _, c := range r.clients {
+			r.playerTime(w.players[c].Name, 1)
+		}
+	})
+	if runs.Load() != 2 || w.profiler.players[""] != 3 {
+		t.Errorf("runs = %d, profiled %v", runs.Load(), w.profiler.players)
+	}
+}
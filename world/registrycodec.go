@@ -19,6 +19,9 @@ package world
 import (
 	"bytes"
 	_ "embed"
+	"fmt"
+
+	"github.com/Tnze/go-mc/level/biome"
 
 	"github.com/Tnze/go-mc/registry"
 
@@ -36,4 +39,16 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	// Біоми в чанках зберігаються як biome.Type з go-mc, і саме ці числа летять клієнту.
+	// А в кодеку біоми пронумеровані за алфавітом, тому клієнт бачив би не той біом.
+	// Перенумеровуємо реєстр, щоб номери збігались з biome.Type
+	biomes := NetworkCodec.WorldGenBiome.Value
+	for i := range biomes {
+		var b biome.Type
+		if err := b.UnmarshalText([]byte(biomes[i].Name)); err != nil {
+			panic(fmt.Errorf("biome %s: %w", biomes[i].Name, err))
+		}
+		biomes[i].ID = int32(b)
+	}
 }
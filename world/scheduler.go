@@ -0,0 +1,162 @@
+// Йоу, чат! Зараз розберемо як запустити свій код в тік-горутині!
+// Весь стан світу належить тік-горутині і змінюється тільки під tickLock.
+// Замість того, щоб самому ловити tickLock, код гри ставить задачу в планувальник:
+// виконати в наступному тіку, через N тіків або повторювати кожні N тіків.
+// Задача виконується в тік-горутині, вже під tickLock, тому може вільно
+// чіпати чанки, гравців і сутності.
+// Важку роботу (диск, мережа) можна винести в RunAsync: вона виконується
+// в окремій горутині, а результат повертається в тік-горутину.
+
+package world
+
+import (
+	"container/heap"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// TaskHandle - ручка запланованої задачі, через яку її можна скасувати
+type TaskHandle struct {
+	cancelled atomic.Bool
+}
+
+// Cancel скасовує задачу: якщо вона ще не виконалась, то вже не виконається
+// Повторювана задача більше не повториться. Можна викликати з будь-якої горутини
+func (h *TaskHandle) Cancel() {
+	h.cancelled.Store(true)
+}
+
+// Cancelled повертає true якщо задачу скасовано
+func (h *TaskHandle) Cancelled() bool {
+	return h.cancelled.Load()
+}
+
+// scheduledTask - задача в черзі планувальника
+type scheduledTask struct {
+	run    func()
+	due    uint64 // номер тіку, в якому виконати
+	period uint64 // 0 - виконати один раз
+	seq    uint64 // порядок додавання, щоб задачі одного тіку йшли по черзі
+	handle *TaskHandle
+}
+
+// taskQueue - купа задач, відсортована за тіком виконання
+type taskQueue []*scheduledTask
+
+func (q taskQueue) Len() int { return len(q) }
+func (q taskQueue) Less(i, j int) bool {
+	if q[i].due != q[j].due {
+		return q[i].due < q[j].due
+	}
+	return q[i].seq < q[j].seq
+}
+func (q taskQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *taskQueue) Push(x any)   { *q = append(*q, x.(*scheduledTask)) }
+func (q *taskQueue) Pop() any {
+	old := *q
+	t := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return t
+}
+
+// scheduler - планувальник задач тік-горутини
+// Задачі додаються з будь-якої горутини в incoming,
+// а тік-горутина переносить їх в чергу перед виконанням
+type scheduler struct {
+	mu       sync.Mutex
+	incoming []*scheduledTask
+	seq      uint64
+	now      uint64    // номер останнього виконаного тіку, під mu
+	queue    taskQueue // належить тік-горутині
+}
+
+// add ставить задачу з ручкою h в чергу через delay тіків після поточного
+func (s *scheduler) add(h *TaskHandle, delay, period int, run func()) *TaskHandle {
+	s.mu.Lock()
+	s.seq++
+	s.incoming = append(s.incoming, &scheduledTask{
+		run:    run,
+		due:    s.now + uint64(max(delay, 1)),
+		period: uint64(max(period, 0)),
+		seq:    s.seq,
+		handle: h,
+	})
+	s.mu.Unlock()
+	return h
+}
+
+// runDue виконує всі задачі, час яких настав в тіку n
+// Викликати тільки з тік-горутини під tickLock
+func (s *scheduler) runDue(n uint64, log *zap.Logger) {
+	s.mu.Lock()
+	s.now = n
+	for _, t := range s.incoming {
+		heap.Push(&s.queue, t)
+	}
+	s.incoming = s.incoming[:0]
+	s.mu.Unlock()
+
+	for len(s.queue) > 0 && s.queue[0].due <= n {
+		t := heap.Pop(&s.queue).(*scheduledTask)
+		if t.handle.Cancelled() {
+			continue
+		}
+		runTask(t.run, log)
+		// Задача могла скасувати сама себе
+		if t.period > 0 && !t.handle.Cancelled() {
+			t.due = n + t.period
+			s.mu.Lock()
+			s.seq++
+			t.seq = s.seq
+			s.mu.Unlock()
+			heap.Push(&s.queue, t)
+		}
+	}
+}
+
+// runTask виконує задачу, не даючи її паніці зупинити тіки
+func runTask(run func(), log *zap.Logger) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("Scheduled task panic", zap.Any("panic", r), zap.StackSkip("stack", 2))
+		}
+	}()
+	run()
+}
+
+// RunNextTick виконує f на початку наступного тіку
+// f виконується в тік-горутині під tickLock, тому не може викликати методи World,
+// які самі беруть tickLock (SpawnPositionAndAngle, AddPlayer і т.д.)
+func (w *World) RunNextTick(f func()) *TaskHandle {
+	return w.scheduler.add(&TaskHandle{}, 1, 0, f)
+}
+
+// RunAfter виконує f через ticks тіків
+func (w *World) RunAfter(ticks int, f func()) *TaskHandle {
+	return w.scheduler.add(&TaskHandle{}, ticks, 0, f)
+}
+
+// RunRepeating виконує f через delay тіків, а потім кожні period тіків, поки задачу не скасують
+func (w *World) RunRepeating(delay, period int, f func()) *TaskHandle {
+	return w.scheduler.add(&TaskHandle{}, delay, max(period, 1), f)
+}
+
+// RunAsync виконує work в окремій горутині, без tickLock
+// Функцію, яку поверне work, виконує вже тік-горутина в найближчому тіку
+// Так можна читати файли чи ходити в мережу, а результат застосовувати до світу
+// Якщо задачу скасують до того, як результат дійде до тіку, він буде відкинутий
+func (w *World) RunAsync(work func() (then func())) *TaskHandle {
+	h := &TaskHandle{}
+	go func() {
+		var then func()
+		runTask(func() { then = work() }, w.log)
+		if then == nil || h.Cancelled() {
+			return
+		}
+		// Передаємо результат в тік-горутину з тією ж ручкою скасування
+		w.scheduler.add(h, 1, 0, then)
+	}()
+	return h
+}
@@ -0,0 +1,73 @@
+package world
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestScheduler(t *testing.T) {
+	var s scheduler
+	var got []string
+	log := zap.NewNop()
+	add := func(delay, period int, name string) *TaskHandle {
+		return s.add(&TaskHandle{}, delay, period, func() { got = append(got, name) })
+	}
+
+	add(1, 0, "next")
+	// Задача, додана з тіку, виконується в наступному
+	s.add(&TaskHandle{}, 3, 0, func() {
+		got = append(got, "after3")
+		s.add(&TaskHandle{}, 1, 0, func() { got = append(got, "self") })
+	})
+	repeat := add(2, 2, "repeat")
+	cancelled := add(2, 0, "cancelled")
+	cancelled.Cancel()
+	add(1, 0, "panic")
+	s.incoming[len(s.incoming)-1].run = func() { panic("boom") }
+
+	want := map[uint64][]string{
+		1: {"next"},
+		2: {"repeat"},
+		3: {"after3"},
+		4: {"repeat", "self"},
+		5: nil,
+		6: {"repeat"},
+		7: nil,
+		8: nil,
+	}
+	for n := uint64(1); n <= 8; n++ {
+		got = nil
+		s.runDue(n, log)
+		if n == 6 {
+			repeat.Cancel()
+		}
+		if !reflect.DeepEqual(got, want[n]) {
+			t.Errorf("tick %d: ran %v, want %v", n, got, want[n])
+		}
+	}
+}
+
+func TestRunAsync(t *testing.T) {
+	w := &World{log: zap.NewNop()}
+	offThread := make(chan bool, 1)
+	var applied int
+	w.RunAsync(func() func() {
+		offThread <- true
+		return func() { applied = 42 }
+	})
+	cancelled := w.RunAsync(func() func() {
+		time.Sleep(10 * time.Millisecond)
+		return func() { applied = -1 }
+	})
+	cancelled.Cancel()
+
+	<-offThread
+	time.Sleep(50 * time.Millisecond)
+	w.scheduler.runDue(1, w.log)
+	if applied != 42 {
+		t.Errorf("async result applied = %d, want 42", applied)
+	}
+}
@@ -0,0 +1,296 @@
+// Йоу, чат! А тепер зберемо з шуму справжній світ!
+// Для кожної колонки блоків ми рахуємо висоту поверхні з трьох шумів:
+// континенти (де суша, а де океан), пагорби і дрібні нерівності.
+// Ще два шуми - температура і вологість - обирають біом,
+// а біом вирішує, чим накрити поверхню: травою, піском чи снігом.
+// Печери - це тривимірний шум: там, де два шуми одночасно близькі до нуля,
+// утворюються довгі звивисті тунелі.
+
+package world
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sync"
+
+	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/level/biome"
+	"github.com/Tnze/go-mc/level/block"
+)
+
+// seaLevel - рівень моря, як у ванільному overworld
+const seaLevel = 62
+
+// NoiseGenerator генерує рельєф з пагорбами, океанами, печерами і біомами
+// Один і той самий сід завжди дає однакові чанки
+type NoiseGenerator struct {
+	noises sync.Map // сід -> *terrainNoise, щоб не створювати шуми для кожного чанку
+}
+
+// terrainNoise - всі шуми, потрібні для генерації, створені з одного сіда
+type terrainNoise struct {
+	seed        int64
+	continents  *octaveNoise // де суша, а де океан
+	hills       *octaveNoise // пагорби і гори
+	detail      *octaveNoise // дрібні нерівності поверхні
+	temperature *octaveNoise // температура для вибору біому
+	humidity    *octaveNoise // вологість для вибору біому
+	caveA       *octaveNoise // перший шум тунелів
+	caveB       *octaveNoise // другий шум тунелів
+}
+
+// newTerrainNoise створює шуми з сіда світу
+// Кожен шум отримує свій генератор, тому додавання нового шуму не змінює старі
+func newTerrainNoise(seed int64) *terrainNoise {
+	sub := func(salt uint64, octaves int) *octaveNoise {
+		return newOctaveNoise(newRandom(int64(mix64(uint64(seed)^salt))), octaves)
+	}
+	return &terrainNoise{
+		seed:        seed,
+		continents:  sub(0x636f6e74, 5),
+		hills:       sub(0x68696c6c, 4),
+		detail:      sub(0x64657461, 3),
+		temperature: sub(0x74656d70, 3),
+		humidity:    sub(0x68756d69, 3),
+		caveA:       sub(0x63617641, 2),
+		caveB:       sub(0x63617642, 2),
+	}
+}
+
+// terrainNoise повертає шуми для сіда, створюючи їх при першому зверненні
+func (g *NoiseGenerator) terrainNoise(seed int64) *terrainNoise {
+	if n, ok := g.noises.Load(seed); ok {
+		return n.(*terrainNoise)
+	}
+	n, _ := g.noises.LoadOrStore(seed, newTerrainNoise(seed))
+	return n.(*terrainNoise)
+}
+
+// column - результат розрахунку однієї колонки блоків
+type column struct {
+	height int        // висота найвищого твердого блоку
+	biome  biome.Type // біом колонки
+}
+
+// Біоми, які вміє генерувати NoiseGenerator
+var (
+	biomePlains         = biomeByName("minecraft:plains")
+	biomeDesert         = biomeByName("minecraft:desert")
+	biomeSnowyPlains    = biomeByName("minecraft:snowy_plains")
+	biomeBeach          = biomeByName("minecraft:beach")
+	biomeSnowyBeach     = biomeByName("minecraft:snowy_beach")
+	biomeOcean          = biomeByName("minecraft:ocean")
+	biomeDeepOcean      = biomeByName("minecraft:deep_ocean")
+	biomeFrozenOcean    = biomeByName("minecraft:frozen_ocean")
+	biomeWindsweptHills = biomeByName("minecraft:windswept_hills")
+)
+
+// column рахує висоту і біом колонки з абсолютними координатами x, z
+func (n *terrainNoise) column(x, z int) column {
+	fx, fz := float64(x), float64(z)
+	cont := n.continents.sample2(fx/640, fz/640)
+	hills := n.hills.sample2(fx/160, fz/160)
+	detail := n.detail.sample2(fx/32, fz/32)
+
+	// Континенти задають базову висоту: нижче нуля - океани, вище - суша
+	// Чим далі вглиб суші, тим вищі пагорби, а на самих високих місцях - гори
+	// Сума октав рідко виходить за ±0.6, тому розтягуємо континенти
+	cont = float64(cont*1.6) + 0.1
+	base := seaLevel + 2 + float64(cont*40)
+	amp := 6 + math.Max(0, cont)*30 + math.Max(0, float64(cont-0.4))*200
+	h := int(math.Floor(base + float64(hills*amp) + float64(detail*3)))
+	h = min(max(h, chunkMinY+8), chunkMinY+chunkHeight-16)
+
+	temp := n.temperature.sample2(fx/900, fz/900)
+	hum := n.humidity.sample2(fx/900, fz/900)
+	var b biome.Type
+	switch {
+	case h < seaLevel-18:
+		b = biomeDeepOcean
+	case h < seaLevel-1 && temp < -0.2:
+		b = biomeFrozenOcean
+	case h < seaLevel-1:
+		b = biomeOcean
+	case h <= seaLevel+2 && temp < -0.2:
+		b = biomeSnowyBeach
+	case h <= seaLevel+2:
+		b = biomeBeach
+	case h > 100:
+		b = biomeWindsweptHills
+	case temp > 0.2 && hum < 0.1:
+		b = biomeDesert
+	case temp < -0.2:
+		b = biomeSnowyPlains
+	default:
+		b = biomePlains
+	}
+	return column{height: h, biome: b}
+}
+
+// isCave повертає true якщо блок з абсолютними координатами вирізаний печерою
+// Тунель проходить там, де обидва шуми майже нульові - це перетин двох "поверхонь"
+func (n *terrainNoise) isCave(x, y, z int) bool {
+	fx, fy, fz := float64(x)/56, float64(y)/28, float64(z)/56
+	a := n.caveA.sample3(fx, fy, fz)
+	if math.Abs(a) > 0.08 {
+		return false // швидка перевірка, щоб не рахувати другий шум
+	}
+	b := n.caveB.sample3(fx, fy, fz)
+	return float64(a*a)+float64(b*b) < 0.0045
+}
+
+// Блоки, з яких будується рельєф
+var (
+	stateStone      = block.ToStateID[block.Stone{}]
+	stateDeepslate  = block.ToStateID[block.Deepslate{Axis: block.Y}]
+	stateBedrock    = block.ToStateID[block.Bedrock{}]
+	stateWater      = block.ToStateID[block.Water{}]
+	stateLava       = block.ToStateID[block.Lava{}]
+	stateDirt       = block.ToStateID[block.Dirt{}]
+	stateGrass      = block.ToStateID[block.GrassBlock{}]
+	stateSnowyGrass = block.ToStateID[block.GrassBlock{Snowy: true}]
+	stateSnowLayer  = block.ToStateID[block.Snow{Layers: 1}]
+	stateSand       = block.ToStateID[block.Sand{}]
+	stateSandstone  = block.ToStateID[block.Sandstone{}]
+	stateGravel     = block.ToStateID[block.Gravel{}]
+	stateIce        = block.ToStateID[block.Ice{}]
+)
+
+const (
+	lavaLevel           = chunkMinY + 9 // печери нижче цього рівня заповнені лавою
+	caveSurfaceDistance = 5             // печери не підходять до поверхні ближче
+)
+
+// surface - чим накривається поверхня в біомі
+type surface struct {
+	top, filler block.StateID // верхній блок і блоки під ним
+	depth       int           // скільки блоків займає top+filler
+	snow        bool          // чи лежить зверху шар снігу
+}
+
+// biomeSurface повертає поверхню для біому
+// В горах поверхня залежить ще й від висоти: вище - голе каміння і сніг
+func biomeSurface(b biome.Type, height int) surface {
+	switch b {
+	case biomeDesert:
+		return surface{top: stateSand, filler: stateSandstone, depth: 6}
+	case biomeBeach, biomeOcean, biomeFrozenOcean:
+		return surface{top: stateSand, filler: stateSand, depth: 4}
+	case biomeSnowyBeach:
+		return surface{top: stateSand, filler: stateSand, depth: 4, snow: true}
+	case biomeDeepOcean:
+		return surface{top: stateGravel, filler: stateGravel, depth: 3}
+	case biomeSnowyPlains:
+		return surface{top: stateSnowyGrass, filler: stateDirt, depth: 4, snow: true}
+	case biomeWindsweptHills:
+		if height > 150 {
+			return surface{top: stateStone, filler: stateStone, depth: 1, snow: true}
+		}
+		if height > 130 {
+			return surface{top: stateStone, filler: stateStone, depth: 1}
+		}
+	}
+	return surface{top: stateGrass, filler: stateDirt, depth: 4}
+}
+
+func (g *NoiseGenerator) Generate(pos [2]int32, seed int64) *level.Chunk {
+	n := g.terrainNoise(seed)
+	c := level.EmptyChunk(chunkSections)
+	x0, z0 := int(pos[0])*16, int(pos[1])*16
+
+	var columns [16 * 16]column
+	for z := 0; z < 16; z++ {
+		for x := 0; x < 16; x++ {
+			columns[z*16+x] = n.column(x0+x, z0+z)
+		}
+	}
+
+	for z := 0; z < 16; z++ {
+		for x := 0; x < 16; x++ {
+			n.fillColumn(c, x, z, x0+x, z0+z, columns[z*16+x])
+		}
+	}
+
+	// Біоми в чанку зберігаються сіткою 4x4x4, беремо колонку в центрі кожної клітинки
+	for i := range c.Sections {
+		sec := &c.Sections[i]
+		for cz := 0; cz < 4; cz++ {
+			for cx := 0; cx < 4; cx++ {
+				b := columns[(cz*4+2)*16+cx*4+2].biome
+				for cy := 0; cy < 4; cy++ {
+					sec.Biomes.Set((cy*4+cz)*4+cx, b)
+				}
+			}
+		}
+	}
+
+	updateHeightMaps(c)
+	c.Status = level.StatusFull
+	return c
+}
+
+// fillColumn заповнює одну колонку блоків чанку
+// x, z - координати всередині чанку, wx, wz - абсолютні координати
+func (n *terrainNoise) fillColumn(c *level.Chunk, x, z, wx, wz int, col column) {
+	set := func(y int, state block.StateID) {
+		sec := &c.Sections[(y-chunkMinY)>>4]
+		sec.SetBlock(sectionIndex(x, y-chunkMinY, z), state)
+	}
+	surf := biomeSurface(col.biome, col.height)
+
+	for y := chunkMinY; y <= col.height; y++ {
+		// Бедрок: суцільний на дні і нерівний на 4 блоки вище
+		if y == chunkMinY || y < chunkMinY+5 && int(posHash(n.seed, wx, y, wz)%5) >= y-chunkMinY {
+			set(y, stateBedrock)
+			continue
+		}
+		// Печери
+		if y < col.height-caveSurfaceDistance && n.isCave(wx, y, wz) {
+			if y <= lavaLevel {
+				set(y, stateLava)
+			}
+			continue
+		}
+		var state block.StateID
+		switch {
+		case y > col.height-surf.depth && y == col.height:
+			state = surf.top
+		case y > col.height-surf.depth:
+			state = surf.filler
+		case y < 0 || y < 8 && int(posHash(n.seed, wx, y, wz)%8) >= y:
+			state = stateDeepslate // плавний перехід від каменю до глибинного сланцю
+		default:
+			state = stateStone
+		}
+		// Під водою трава перетворюється на землю
+		if col.height < seaLevel && state == stateGrass {
+			state = stateDirt
+		}
+		set(y, state)
+	}
+
+	// Вода до рівня моря, а в холодних океанах - лід на поверхні
+	for y := col.height + 1; y <= seaLevel; y++ {
+		if y == seaLevel && col.biome == biomeFrozenOcean {
+			set(y, stateIce)
+		} else {
+			set(y, stateWater)
+		}
+	}
+	if surf.snow && col.height >= seaLevel {
+		set(col.height+1, stateSnowLayer)
+	}
+}
+
+// hashSeed рахує хеш сіда, який клієнт використовує для розподілу біомів
+// Ванільний сервер бере перші 8 байт SHA-256 від сіда в little-endian
+// і відправляє їх як long, тому переводимо в big-endian для пакету
+func hashSeed(seed int64) [8]byte {
+	var in [8]byte
+	binary.LittleEndian.PutUint64(in[:], uint64(seed))
+	sum := sha256.Sum256(in[:])
+	var out [8]byte
+	binary.BigEndian.PutUint64(out[:], binary.LittleEndian.Uint64(sum[:8]))
+	return out
+}
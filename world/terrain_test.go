@@ -0,0 +1,84 @@
+// Йоу, чат! Золоті тести генератора рельєфу.
+// Однаковий сід має завжди давати однаковий світ, тому ми зберігаємо
+// відбитки кількох чанків і порівнюємо з ними. Якщо ви свідомо змінили
+// генератор - оновіть відбитки, але пам'ятайте що на існуючих світах
+// з'являться шви між старими і новими чанками.
+
+package world
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/Tnze/go-mc/level"
+)
+
+// chunkFingerprint рахує хеш всіх блоків, біомів і карт висот чанку
+func chunkFingerprint(c *level.Chunk) string {
+	h := sha256.New()
+	var buf [8]byte
+	for i := range c.Sections {
+		sec := &c.Sections[i]
+		for j := 0; j < 16*16*16; j++ {
+			binary.LittleEndian.PutUint32(buf[:4], uint32(sec.GetBlock(j)))
+			h.Write(buf[:4])
+		}
+		for j := 0; j < 4*4*4; j++ {
+			binary.LittleEndian.PutUint32(buf[:4], uint32(sec.Biomes.Get(j)))
+			h.Write(buf[:4])
+		}
+	}
+	for _, hm := range []*level.BitStorage{c.HeightMaps.WorldSurface, c.HeightMaps.MotionBlocking, c.HeightMaps.OceanFloor} {
+		for _, v := range hm.Raw() {
+			binary.LittleEndian.PutUint64(buf[:], v)
+			h.Write(buf[:])
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// TestNoiseGenerator_golden порівнює згенеровані чанки з відомими відбитками
+func TestNoiseGenerator_golden(t *testing.T) {
+	const seed = 20230422
+	golden := []struct {
+		pos  [2]int32
+		want string
+	}{
+		{[2]int32{0, 0}, "f26bea74da8835fc8da5e7d8247f8df71ada77512a2d7ca08c45523aabb2f3f9"},
+		{[2]int32{-5, 12}, "bdf73842b93cd021ed47af31a99fc40bd5f25a742bdd1b93dc2815f3ceb6b7ee"},
+		{[2]int32{31, -31}, "610f75fa8f6212baad9d0598caa91fff8a1c216eca300b451ef6359331971d39"},
+		{[2]int32{-250, 400}, "37b3573a598d4c57bb3d77b8ce2ebcdf38ab4b4af61308e2016ad7f906216823"},
+	}
+	g := &NoiseGenerator{}
+	for _, tt := range golden {
+		if got := chunkFingerprint(g.Generate(tt.pos, seed)); got != tt.want {
+			t.Errorf("chunk %v: fingerprint %s, want %s", tt.pos, got, tt.want)
+		}
+	}
+}
+
+// TestNoiseGenerator_deterministic перевіряє що результат залежить тільки від позиції і сіда
+func TestNoiseGenerator_deterministic(t *testing.T) {
+	pos := [2]int32{7, -3}
+	a := chunkFingerprint((&NoiseGenerator{}).Generate(pos, 42))
+	// Інший генератор, і перед цим він вже генерував інші чанки
+	g := &NoiseGenerator{}
+	g.Generate([2]int32{100, 100}, 42)
+	g.Generate(pos, 43)
+	if b := chunkFingerprint(g.Generate(pos, 42)); a != b {
+		t.Error("same seed and position give different chunks")
+	}
+	if b := chunkFingerprint(g.Generate(pos, 43)); a == b {
+		t.Error("different seeds give the same chunk")
+	}
+}
+
+// TestHashSeed перевіряє хеш сіда для пакету входу
+func TestHashSeed(t *testing.T) {
+	got := hashSeed(20230422)
+	if want := "642361c48cad9b6f"; hex.EncodeToString(got[:]) != want {
+		t.Errorf("hashed seed is %x, want %s", got, want)
+	}
+}
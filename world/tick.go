@@ -31,32 +31,53 @@ import (
 	"FlowyCore/world/internal/bvh"
 )
 
-// tickLoop запускає головний цикл оновлення світу
-// Викликається кожні 50мс (20 разів на секунду)
-func (w *World) tickLoop() {
-	var n uint
-	for range time.Tick(time.Microsecond * 20) {
-		w.tick(n)
-		n++
-	}
-}
-
 // tick виконує одне оновлення світу
 // Розділений на підтіки для різних систем
 func (w *World) tick(n uint) {
 	w.tickLock.Lock()
 	defer w.tickLock.Unlock()
+	if w.closed {
+		return
+	}
+
+	// Кожна фаза заміряється профайлером
+	prof := &w.profiler
+	t := prof.begin()
+
+	// Рахуємо час і погоду світу і виконуємо задачі, заплановані на цей тік
+	w.advanceTime()
+	w.advanceWeather()
+	w.scheduler.runDue(uint64(n), w.log)
+	t = prof.phase(phaseTasks, t)
+
+	// Забираємо чанки, які воркери вже завантажили
+	w.collectLoadedChunks()
+	w.loadBlockChunks()
+	t = prof.phase(phaseCollectChunks, t)
+
+	// Ділимо гравців на регіони, які можна тікати паралельно
+	w.subtickUpdateViews()
+	regions := w.buildRegions()
+	t = prof.phase(phaseRegions, t)
 
 	if n%8 == 0 { // кожен 8-й тік (4 рази на секунду)
-		w.subtickChunkLoad() // оновлюємо завантаження чанків
+		w.subtickChunkLoad(regions) // оновлюємо завантаження чанків
+		t = prof.phase(phaseChunkLoad, t)
 	}
-	w.subtickUpdatePlayers()  // оновлюємо стан гравців
-	w.subtickUpdateEntities() // оновлюємо стан сутностей
+	w.runRegions(regions, w.subtickUpdatePlayers) // оновлюємо стан гравців
+	w.subtickBlockActions()                       // ламаємо і ставимо блоки вже після паралельних регіонів
+	t = prof.phase(phasePlayers, t)
+	w.runRegions(regions, w.subtickUpdateEntities) // оновлюємо стан сутностей
+	t = prof.phase(phaseEntities, t)
+	w.subtickBlockUpdates() // розсилаємо змінені блоки
+	prof.phase(phaseBlocks, t)
+
+	prof.end(w.log, n)
 }
 
 // subtickChunkLoad відповідає за завантаження та вивантаження чанків
 // Викликається 4 рази на секунду для оптимізації навантаження
-func (w *World) subtickChunkLoad() {
+func (w *World) subtickChunkLoad(regions []tickRegion) {
 	// Оновлюємо центр завантаження для кожного гравця
 	for c, p := range w.players {
 		x := int32(p.Position[0]) >> 4 // конвертуємо координати в чанки
@@ -69,21 +90,76 @@ func (w *World) subtickChunkLoad() {
 	}
 
 	// Завантажуємо нові чанки для кожного гравця
-LoadChunk:
+	// Чанки, яких ще немає в пам'яті, віддаємо воркерам і переходимо до наступних.
+	// Гравець отримає їх в одному з наступних підтіків, коли воркер закінчить
+	canRequest := true // чи залишився глобальний бюджет на завантаження
 	for viewer, loader := range w.loaders {
 		loader.calcLoadingQueue() // розраховуємо які чанки потрібно завантажити
 		for _, pos := range loader.loadQueue {
-			if !loader.limiter.Allow() { // перевіряємо ліміт завантаження
-				break
-			}
-			if _, ok := w.chunks[pos]; !ok {
-				if !w.loadChunk(pos) {
-					break LoadChunk // досягнуто глобальний ліміт
+			switch w.chunkState(pos) {
+			case chunkUnloaded:
+				if canRequest {
+					canRequest = w.requestChunk(pos)
 				}
+				continue
+			case chunkPending:
+				continue // чанк ще завантажується
+			}
+			if !loader.limiter.Allow() { // перевіряємо ліміт відправки гравцю
+				break
 			}
 			loader.loaded[pos] = struct{}{}
+			w.chunks[pos].AddViewer(viewer)
+			loader.sendQueue = append(loader.sendQueue, pos)
+		}
+	}
+
+	// Самі чанки відправляємо по регіонах, бо це найдовша частина підтіку
+	w.runRegions(regions, w.sendChunks)
+
+	// Вивантажуємо непотрібні чанки
+	for viewer, loader := range w.loaders {
+		loader.calcUnusedChunks() // шукаємо чанки поза зоною видимості
+		for _, pos := range loader.unloadQueue {
+			delete(loader.loaded, pos)
+			if !w.chunks[pos].RemoveViewer(viewer) {
+				w.log.Panic("viewer is not found in the loaded chunk")
+			}
+			viewer.ViewChunkUnload(pos)
+		}
+	}
+
+	// Вивантажуємо чанки без спостерігачів
+	// Чанк, з яким недавно працювали GetBlock чи SetBlock, трохи почекає,
+	// інакше він би вантажився і вивантажувався на кожен такий виклик
+	var unloadQueue [][2]int32
+	for pos, chunk := range w.chunks {
+		if chunk.requested.Swap(false) {
+			chunk.keepAlive = blockChunkKeepAlive
+		}
+		if len(chunk.viewers) > 0 {
+			continue
+		}
+		if chunk.keepAlive > 0 {
+			chunk.keepAlive--
+			continue
+		}
+		unloadQueue = append(unloadQueue, pos)
+	}
+	for i := range unloadQueue {
+		w.unloadChunk(unloadQueue[i])
+	}
+	if len(unloadQueue) > 0 {
+		w.flushChunks() // записуємо змінені регіони одним заходом
+	}
+}
+
+// sendChunks відправляє гравцям регіону чанки з їх черг
+func (w *World) sendChunks(r *tickRegion) {
+	for _, viewer := range r.clients {
+		loader := w.loaders[viewer]
+		for _, pos := range loader.sendQueue {
 			lc := w.chunks[pos]
-			lc.AddViewer(viewer)
 			lc.Lock()
 
 			// Перевіряємо чанк перед відправкою
@@ -99,53 +175,44 @@ LoadChunk:
 					zap.String("status", string(lc.Chunk.Status)))
 			}
 
+			start := time.Now()
 			viewer.ViewChunkLoad(pos, lc.Chunk)
+			r.chunkSent(pos, loaderName(loader), time.Since(start))
 			lc.Unlock()
 		}
+		loader.sendQueue = loader.sendQueue[:0]
 	}
+}
 
-	// Вивантажуємо непотрібні чанки
-	for viewer, loader := range w.loaders {
-		loader.calcUnusedChunks() // шукаємо чанки поза зоною видимості
-		for _, pos := range loader.unloadQueue {
-			delete(loader.loaded, pos)
-			if !w.chunks[pos].RemoveViewer(viewer) {
-				w.log.Panic("viewer is not found in the loaded chunk")
-			}
-			viewer.ViewChunkUnload(pos)
+// subtickUpdateViews оновлює зони видимості гравців в BVH дереві
+// Дерево спільне для всіх регіонів, тому змінюється до їх запуску
+func (w *World) subtickUpdateViews() {
+	for _, p := range w.players {
+		if !p.Inputs.TryLock() {
+			continue
 		}
-	}
-
-	// Вивантажуємо чанки без спостерігачів
-	var unloadQueue [][2]int32
-	for pos, chunk := range w.chunks {
-		if len(chunk.viewers) == 0 {
-			unloadQueue = append(unloadQueue, pos)
+		if p.ViewDistance != int32(p.Inputs.ViewDistance) {
+			p.ViewDistance = int32(p.Inputs.ViewDistance)
+			p.view = w.playerViews.Insert(p.getView(), w.playerViews.Delete(p.view))
 		}
-	}
-	for i := range unloadQueue {
-		w.unloadChunk(unloadQueue[i])
+		p.Inputs.Unlock()
 	}
 }
 
-// subtickUpdatePlayers оновлює стан всіх гравців
+// subtickUpdatePlayers оновлює стан гравців регіону
 // Обробляє рух, телепортацію та зону видимості
-func (w *World) subtickUpdatePlayers() {
-	for c, p := range w.players {
+func (w *World) subtickUpdatePlayers(r *tickRegion) {
+	for _, c := range r.clients {
+		p := w.players[c]
 		if !p.Inputs.TryLock() {
 			continue
 		}
+		start := time.Now()
 		inputs := &p.Inputs
 
-		// Оновлюємо радіус видимості
-		if p.ViewDistance != int32(inputs.ViewDistance) {
-			p.ViewDistance = int32(inputs.ViewDistance)
-			p.view = w.playerViews.Insert(p.getView(), w.playerViews.Delete(p.view))
-		}
-
 		// Видаляємо сутності поза зоною видимості
 		for id, e := range p.EntitiesInView {
-			if !p.view.Box.WithIn(vec3d(e.Position)) {
+			if !p.view.Box.WithIn(vec3d(e.Position)) || !e.inTrackingRange(p.Position) {
 				delete(p.EntitiesInView, id)
 				p.view.Value.ViewRemoveEntities([]int32{id})
 			}
@@ -187,86 +254,148 @@ func (w *World) subtickUpdatePlayers() {
 				c.SendDisconnect(chat.TranslateMsg("multiplayer.disconnect.invalid_player_movement"))
 			}
 		}
+		// Присідання, біг і елітри
+		w.updatePose(p)
+		w.playAnimations(p)
 		p.Inputs.Unlock()
+		r.playerTime(p.Name, time.Since(start))
 	}
 }
 
-// subtickUpdateEntities оновлює стан всіх сутностей
-// Наразі обробляє тільки гравців, бо інших сутностей ще немає
-func (w *World) subtickUpdateEntities() {
-	for _, e := range w.players {
-		// Розраховуємо дельту позиції та повороту
-		var delta [3]int16
-		var rot [2]int8
-		if e.Position != e.pos0 { // TODO: відправляти пакет телепортації якщо відстань > 8
-			delta = [3]int16{
-				int16((e.pos0[0] - e.Position[0]) * 32 * 128),
-				int16((e.pos0[1] - e.Position[1]) * 32 * 128),
-				int16((e.pos0[2] - e.Position[2]) * 32 * 128),
-			}
-		}
-		if e.Rotation != e.rot0 {
-			rot = [2]int8{
-				int8(e.rot0[0] * 256 / 360),
-				int8(e.rot0[1] * 256 / 360),
+// subtickUpdateEntities оновлює стан сутностей регіону: гравців та інших сутностей
+func (w *World) subtickUpdateEntities(r *tickRegion) {
+	for _, c := range r.clients {
+		start := time.Now()
+		p := w.players[c]
+		w.updateEntity(&p.Entity, p, func(v EntityViewer) { v.ViewAddPlayer(p) })
+		w.broadcastEntityData(&p.Entity, c)
+		r.playerTime(p.Name, time.Since(start))
+	}
+	for _, e := range r.entities {
+		w.updateEntity(&e.Entity, nil, func(v EntityViewer) { v.ViewAddEntity(e) })
+		w.broadcastEntityData(&e.Entity, nil)
+	}
+}
+
+// broadcastEntityData відправляє змінені метадані сутності всім, хто її бачить
+// self - клієнт самого гравця, бо він теж має бачити свою позу, або nil для інших сутностей
+func (w *World) broadcastEntityData(e *Entity, self EntityViewer) {
+	if !e.Metadata.Dirty() {
+		return
+	}
+	data := e.Metadata.TakeDirty()
+	if self != nil {
+		self.ViewEntityData(e.EntityID, data)
+	}
+	w.playerViews.Find(bvh.TouchPoint[vec3d, aabb3d](vec3d(e.Position)),
+		func(n *playerViewNode) bool {
+			if _, ok := n.Value.EntitiesInView[e.EntityID]; ok {
+				n.Value.ViewEntityData(e.EntityID, data)
 			}
+			return true
+		},
+	)
+}
+
+// updateEntity переносить сутність в нову позицію і повідомляє гравців, які її бачать
+// self - гравець, якому не треба надсилати його власні рухи, spawn - як показати сутність новому глядачу
+func (w *World) updateEntity(e *Entity, self *Player, spawn func(v EntityViewer)) {
+	// Новий глядач одразу отримує всі метадані сутності
+	spawnWithData := func(v EntityViewer) {
+		spawn(v)
+		if data := e.Metadata.All(); len(data) > 0 {
+			v.ViewEntityData(e.EntityID, data)
 		}
+	}
 
-		// Шукаємо гравців у зоні видимості
-		cond := bvh.TouchPoint[vec3d, aabb3d](vec3d(e.Position))
-		w.playerViews.Find(cond,
-			func(n *playerViewNode) bool {
-				if n.Value.Player == e {
-					return true // не надсилаємо гравцю його власні рухи
-				}
-				// Додаємо сутність в список видимих
-				if _, ok := n.Value.EntitiesInView[e.EntityID]; !ok {
-					n.Value.ViewAddPlayer(e)
-					n.Value.EntitiesInView[e.EntityID] = &e.Entity
-				}
-				return true
-			},
-		)
-
-		// Вибираємо тип пакету руху
-		var sendMove func(v EntityViewer)
-		switch {
-		case e.Position != e.pos0 && e.Rotation != e.rot0:
-			sendMove = func(v EntityViewer) {
-				v.ViewMoveEntityPosAndRot(e.EntityID, delta, rot, bool(e.OnGround))
-				v.ViewRotateHead(e.EntityID, rot[0])
+	// Розраховуємо дельту позиції та повороту
+	// Відносний рух не влазить в int16 для переміщень далі ~8 блоків,
+	// а ще глядачі час від часу мають отримати точну позицію - тоді телепортуємо
+	moved, turned := e.Position != e.pos0, e.Rotation != e.rot0
+	rot := [2]int8{ToAngle(e.rot0[0]), ToAngle(e.rot0[1])}
+	var delta [3]int16
+	e.sinceSync++
+	teleport := e.sinceSync >= entityResyncInterval
+	if moved && !teleport {
+		var ok bool
+		delta, ok = moveDelta(e.Position, e.pos0)
+		teleport = !ok
+	}
+
+	// Шукаємо гравців у зоні видимості
+	cond := bvh.TouchPoint[vec3d, aabb3d](vec3d(e.Position))
+	w.playerViews.Find(cond,
+		func(n *playerViewNode) bool {
+			if n.Value.Player == self {
+				return true // не надсилаємо гравцю його власні рухи
 			}
-		case e.Position != e.pos0:
-			sendMove = func(v EntityViewer) {
-				v.ViewMoveEntityPos(e.EntityID, delta, bool(e.OnGround))
+			if !e.inTrackingRange(n.Value.Position) {
+				return true // сутність задалеко, щоб її бачити
 			}
-		case e.Rotation != e.rot0:
-			sendMove = func(v EntityViewer) {
-				v.ViewMoveEntityRot(e.EntityID, rot, bool(e.OnGround))
-				v.ViewRotateHead(e.EntityID, rot[0])
+			// Додаємо сутність в список видимих
+			if _, ok := n.Value.EntitiesInView[e.EntityID]; !ok {
+				spawnWithData(n.Value.EntityViewer)
+				n.Value.EntitiesInView[e.EntityID] = e
 			}
-		default:
-			continue
+			return true
+		},
+	)
+
+	// Вибираємо тип пакету руху
+	var sendMove func(v EntityViewer)
+	switch {
+	case teleport:
+		e.sinceSync = 0
+		sendMove = func(v EntityViewer) {
+			v.ViewTeleportEntity(e.EntityID, e.Position, rot, bool(e.OnGround))
+			v.ViewRotateHead(e.EntityID, rot[0])
 		}
+	case moved && turned:
+		sendMove = func(v EntityViewer) {
+			v.ViewMoveEntityPosAndRot(e.EntityID, delta, rot, bool(e.OnGround))
+			v.ViewRotateHead(e.EntityID, rot[0])
+		}
+	case moved:
+		sendMove = func(v EntityViewer) {
+			v.ViewMoveEntityPos(e.EntityID, delta, bool(e.OnGround))
+		}
+	case turned:
+		sendMove = func(v EntityViewer) {
+			v.ViewMoveEntityRot(e.EntityID, rot, bool(e.OnGround))
+			v.ViewRotateHead(e.EntityID, rot[0])
+		}
+	default:
+		return
+	}
 
-		// Оновлюємо позицію
-		e.Position = e.pos0
-		e.Rotation = e.rot0
+	// Оновлюємо позицію
+	e.Position = e.pos0
+	e.Rotation = e.rot0
 
-		// Надсилаємо оновлення всім гравцям в зоні видимості
-		w.playerViews.Find(cond,
-			func(n *playerViewNode) bool {
-				if n.Value.Player == e {
-					return true // пропускаємо самого гравця
-				}
-				if _, ok := n.Value.EntitiesInView[e.EntityID]; ok {
-					sendMove(n.Value.EntityViewer)
-				} else {
-					n.Value.ViewAddPlayer(e)
-					n.Value.EntitiesInView[e.EntityID] = &e.Entity
-				}
+	// Надсилаємо оновлення всім гравцям в зоні видимості
+	w.playerViews.Find(cond,
+		func(n *playerViewNode) bool {
+			if n.Value.Player == self {
+				return true // пропускаємо самого гравця
+			}
+			if !e.inTrackingRange(n.Value.Position) {
 				return true
-			},
-		)
+			}
+			if _, ok := n.Value.EntitiesInView[e.EntityID]; ok {
+				sendMove(n.Value.EntityViewer)
+			} else {
+				spawnWithData(n.Value.EntityViewer)
+				n.Value.EntitiesInView[e.EntityID] = e
+			}
+			return true
+		},
+	)
+}
+
+// loaderName повертає ім'я гравця, якому належить завантажувач, для профайлера
+func loaderName(l *loader) string {
+	if p, ok := l.loaderSource.(*Player); ok {
+		return p.Name
 	}
+	return "?"
 }
@@ -0,0 +1,62 @@
+package world
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEntityMovesMatchServer(t *testing.T) {
+	w := &World{players: make(map[Client]*Player)}
+	v := &fakeClient{}
+	p := &Player{ViewDistance: 10, EntitiesInView: make(map[int32]*Entity)}
+	p.view = w.playerViews.Insert(p.getView(), playerView{v, p})
+	w.players[v] = p
+
+	e := NewGenericEntity(EntityZombie, Position{0.3, 64, 0.7}, Rotation{})
+	region := &tickRegion{entities: []*GenericEntity{e}}
+	check := func(step string) {
+		t.Helper()
+		for i := range v.pos {
+			if math.Abs(v.pos[i]-e.Position[i]) > 1.0/4096 {
+				t.Fatalf("%s: viewer sees %v, server has %v", step, v.pos, e.Position)
+			}
+		}
+	}
+	w.subtickUpdateEntities(region)
+	check("spawn")
+
+	// Багато дрібних кроків: помилки округлення не мають накопичуватись
+	for i := 0; i < entityResyncInterval-10; i++ {
+		e.MoveTo(Position{e.Position[0] + 0.00011, 64, e.Position[2] - 0.00037}, Rotation{}, false)
+		w.subtickUpdateEntities(region)
+	}
+	check("small steps")
+	if v.teleports != 0 {
+		t.Errorf("small steps sent %d teleports", v.teleports)
+	}
+
+	// Стрибок далі 8 блоків не влазить в int16
+	e.MoveTo(Position{e.Position[0] + 9, 70, e.Position[2] - 20}, Rotation{}, false)
+	w.subtickUpdateEntities(region)
+	check("long move")
+	if v.teleports != 1 {
+		t.Errorf("long move sent %d teleports, want 1", v.teleports)
+	}
+
+	// Навіть нерухома сутність періодично отримує точну позицію
+	for i := 0; i < entityResyncInterval; i++ {
+		w.subtickUpdateEntities(region)
+	}
+	if v.teleports != 2 {
+		t.Errorf("got %d teleports after %d idle ticks, want a resync", v.teleports, entityResyncInterval)
+	}
+	check("resync")
+}
+
+func TestToAngle(t *testing.T) {
+	for deg, want := range map[float32]int8{0: 0, 90: 64, 180: -128, 270: -64, -90: -64, 360: 0} {
+		if got := ToAngle(deg); got != want {
+			t.Errorf("ToAngle(%v) = %d, want %d", deg, got, want)
+		}
+	}
+}
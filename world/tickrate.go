@@ -0,0 +1,187 @@
+// Йоу, чат! Зараз розберемо як сервер тримає рівно 20 тіків на секунду!
+// Кожен тік має початися в свій час: 0мс, 50мс, 100мс і так далі.
+// Якщо тік затягнувся, наступні запускаються одразу один за одним,
+// поки сервер не наздожене розклад. Але якщо ми відстали занадто сильно
+// (наприклад, сервер висів на паузі в дебагері), наздоганяти немає сенсу -
+// світ просто "прискорився" б на кілька секунд. Тоді пропущені тіки
+// відкидаються і розклад починається заново, як у ванільному сервері.
+
+package world
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultTPS - стандартна кількість тіків на секунду в Minecraft
+	DefaultTPS = 20
+	// DefaultMaxCatchUpTicks - на скільки тіків можна відстати, перш ніж пропускати їх (2с при 20 TPS)
+	DefaultMaxCatchUpTicks = 40
+
+	tickStatsWindow = 100              // з скількох останніх тіків рахуємо TPS і MSPT
+	lagWarnInterval = 15 * time.Second // як часто можна писати в лог про відставання
+)
+
+// tickScheduler вирішує, коли запускати наступний тік
+type tickScheduler struct {
+	interval   time.Duration // тривалість одного тіку
+	maxCatchUp int           // максимальне відставання в тіках, яке ще наздоганяємо
+	next       time.Time     // коли має початися наступний тік
+}
+
+// newTickScheduler створює розклад, перший тік якого починається в start
+func newTickScheduler(tps, maxCatchUp int, start time.Time) *tickScheduler {
+	if tps <= 0 {
+		tps = DefaultTPS
+	}
+	if maxCatchUp <= 0 {
+		maxCatchUp = DefaultMaxCatchUpTicks
+	}
+	return &tickScheduler{
+		interval:   time.Second / time.Duration(tps),
+		maxCatchUp: maxCatchUp,
+		next:       start,
+	}
+}
+
+// schedule повертає скільки чекати до наступного тіку
+// Якщо wait == 0, тік треба запускати зараз, і розклад вже зсунутий на наступний
+// skipped - скільки тіків відкинуто через завелике відставання
+func (s *tickScheduler) schedule(now time.Time) (wait time.Duration, skipped int) {
+	if now.Before(s.next) {
+		return s.next.Sub(now), 0
+	}
+	// Скільки тіків після поточного вже мали б відбутися
+	if behind := int(now.Sub(s.next) / s.interval); behind > s.maxCatchUp {
+		skipped = behind
+		s.next = now
+	}
+	s.next = s.next.Add(s.interval)
+	return 0, skipped
+}
+
+// TickStats - статистика швидкості тіків
+// Її можна читати з будь-якої горутини, tickLock для цього не потрібен
+type TickStats struct {
+	TargetTPS float64 // скільки тіків на секунду має бути
+	TPS       float64 // реальна кількість тіків на секунду за останні тіки
+	MSPT      float64 // середня тривалість тіку в мілісекундах
+	MaxMSPT   float64 // найдовший тік серед останніх
+	Ticks     uint64  // всього виконано тіків
+	Skipped   uint64  // всього пропущено тіків через відставання
+}
+
+// tickStats збирає тривалість останніх тіків в кільцевий буфер
+type tickStats struct {
+	sync.Mutex
+	target   float64
+	starts   [tickStatsWindow]time.Time     // коли почався кожен тік
+	lengths  [tickStatsWindow]time.Duration // скільки тривав кожен тік
+	ticks    uint64
+	skipped  uint64
+	lastWarn time.Time
+}
+
+// record записує виконаний тік
+func (s *tickStats) record(start time.Time, length time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+	i := s.ticks % tickStatsWindow
+	s.starts[i] = start
+	s.lengths[i] = length
+	s.ticks++
+}
+
+// skip записує пропущені тіки
+// Повертає true, якщо про це варто написати в лог
+func (s *tickStats) skip(n int, now time.Time) bool {
+	s.Lock()
+	defer s.Unlock()
+	s.skipped += uint64(n)
+	if now.Sub(s.lastWarn) < lagWarnInterval {
+		return false
+	}
+	s.lastWarn = now
+	return true
+}
+
+// snapshot рахує TickStats з буфера
+func (s *tickStats) snapshot() TickStats {
+	s.Lock()
+	defer s.Unlock()
+	stats := TickStats{
+		TargetTPS: s.target,
+		Ticks:     s.ticks,
+		Skipped:   s.skipped,
+	}
+	n := min(s.ticks, tickStatsWindow)
+	if n == 0 {
+		return stats
+	}
+
+	var total, longest time.Duration
+	for i := uint64(0); i < n; i++ {
+		total += s.lengths[i]
+		longest = max(longest, s.lengths[i])
+	}
+	stats.MSPT = float64(total) / float64(n) / float64(time.Millisecond)
+	stats.MaxMSPT = float64(longest) / float64(time.Millisecond)
+
+	// TPS - скільки тіків почалося за час від найстарішого до найновішого
+	// Поки сервер наздоганяє розклад, TPS буває більшим за ціль, тому обрізаємо його
+	stats.TPS = s.target
+	if n > 1 {
+		first := s.starts[(s.ticks-n)%tickStatsWindow]
+		last := s.starts[(s.ticks-1)%tickStatsWindow]
+		if elapsed := last.Sub(first); elapsed > 0 {
+			stats.TPS = min(float64(n-1)/elapsed.Seconds(), s.target)
+		}
+	}
+	return stats
+}
+
+// TickStats повертає поточну швидкість тіків світу
+// Для команд на кшталт /tps і для метрик
+func (w *World) TickStats() TickStats {
+	return w.tickStats.snapshot()
+}
+
+// tickLoop запускає головний цикл оновлення світу
+// Тіки йдуть з частотою Config.TPS, поки світ не закриють
+func (w *World) tickLoop(sched *tickScheduler) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	var n uint
+	for {
+		select {
+		case <-w.tickStop:
+			return
+		default:
+		}
+		wait, skipped := sched.schedule(time.Now())
+		if skipped > 0 && w.tickStats.skip(skipped, time.Now()) {
+			w.log.Warn("Can't keep up! Is the server overloaded?",
+				zap.Duration("behind", time.Duration(skipped)*sched.interval),
+				zap.Int("skipped ticks", skipped))
+		}
+		if wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-timer.C:
+			case <-w.tickStop:
+				return
+			}
+			continue
+		}
+
+		start := time.Now()
+		w.tick(n)
+		w.tickStats.record(start, time.Since(start))
+		n++
+	}
+}
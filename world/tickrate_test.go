@@ -0,0 +1,76 @@
+package world
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTickScheduler(t *testing.T) {
+	start := time.Unix(1000, 0)
+	s := newTickScheduler(20, 4, start)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	// Вчасно: тік зараз, наступний через 50мс
+	if wait, skipped := s.schedule(at(0)); wait != 0 || skipped != 0 {
+		t.Fatalf("first tick: wait %v, skipped %d", wait, skipped)
+	}
+	if wait, _ := s.schedule(at(10)); wait != 40*time.Millisecond {
+		t.Fatalf("wait for second tick = %v, want 40ms", wait)
+	}
+
+	// Тік тривав 170мс: наздоганяємо тіки 50, 100 і 150 одразу, а 200 чекаємо
+	for i := 0; i < 3; i++ {
+		if wait, skipped := s.schedule(at(180)); wait != 0 || skipped != 0 {
+			t.Fatalf("catch up tick %d: wait %v, skipped %d", i, wait, skipped)
+		}
+	}
+	if wait, _ := s.schedule(at(180)); wait != 20*time.Millisecond {
+		t.Fatalf("after catch up wait = %v, want 20ms", wait)
+	}
+
+	// Відстали на 10 тіків при ліміті 4: пропускаємо їх і починаємо розклад заново
+	if wait, skipped := s.schedule(at(720)); wait != 0 || skipped != 10 {
+		t.Fatalf("lag: wait %v, skipped %d, want 0, 10", wait, skipped)
+	}
+	if wait, _ := s.schedule(at(720)); wait != 50*time.Millisecond {
+		t.Fatalf("after skip wait = %v, want 50ms", wait)
+	}
+}
+
+func TestTickStats(t *testing.T) {
+	s := tickStats{target: 20}
+	if st := s.snapshot(); st.TPS != 0 || st.Ticks != 0 {
+		t.Fatalf("empty stats: %+v", st)
+	}
+
+	// 200 тіків по 10мс кожні 100мс: 10 TPS, в буфері лише останні 100
+	start := time.Unix(1000, 0)
+	for i := 0; i < 200; i++ {
+		length := 10 * time.Millisecond
+		if i == 150 {
+			length = 80 * time.Millisecond
+		}
+		s.record(start.Add(time.Duration(i)*100*time.Millisecond), length)
+	}
+	s.skip(5, start)
+
+	st := s.snapshot()
+	if math.Abs(st.TPS-10) > 1e-9 {
+		t.Errorf("TPS = %v, want 10", st.TPS)
+	}
+	if math.Abs(st.MSPT-10.7) > 1e-9 {
+		t.Errorf("MSPT = %v, want 10.7", st.MSPT)
+	}
+	if st.MaxMSPT != 80 || st.Ticks != 200 || st.Skipped != 5 || st.TargetTPS != 20 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+
+	// Тіки підряд при наздоганянні не показують TPS більше цілі
+	for i := 0; i < 100; i++ {
+		s.record(start.Add(time.Hour+time.Duration(i)*time.Millisecond), time.Millisecond)
+	}
+	if st := s.snapshot(); st.TPS != 20 {
+		t.Errorf("TPS while catching up = %v, want 20", st.TPS)
+	}
+}
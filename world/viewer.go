@@ -25,6 +25,9 @@ package world
 import (
 	"github.com/Tnze/go-mc/chat"
 	"github.com/Tnze/go-mc/level"
+	"github.com/Tnze/go-mc/level/block"
+
+	"FlowyCore/world/entity"
 )
 
 // Client - головний інтерфейс для взаємодії з клієнтом гравця
@@ -36,14 +39,20 @@ type Client interface {
 	SendDisconnect(reason chat.Message)                                   // відправити повідомлення про відключення
 	SendPlayerPosition(pos [3]float64, rot [2]float32) (teleportID int32) // телепортувати гравця
 	SendSetChunkCacheCenter(chunkPos [2]int32)                            // встановити центр завантаження чанків
+	SendSetTime(worldAge, dayTime int64)                                  // синхронізувати час світу
+	SendGameEvent(event byte, value float32)                              // подія гри, наприклад початок дощу
+	SendBlockChangedAck(sequence int32)                                   // підтвердити зміни блоків до sequence
 }
 
 // ChunkViewer - інтерфейс для роботи з чанками
 // Описує методи для завантаження та вивантаження чанків,
 // які видно гравцю в радіусі прогрузки
 type ChunkViewer interface {
-	ViewChunkLoad(pos level.ChunkPos, c *level.Chunk) // завантажити чанк
-	ViewChunkUnload(pos level.ChunkPos)               // вивантажити чанк
+	ViewChunkLoad(pos level.ChunkPos, c *level.Chunk)                    // завантажити чанк
+	ViewChunkUnload(pos level.ChunkPos)                                  // вивантажити чанк
+	ViewBlockUpdate(pos [3]int32, state block.StateID)                   // змінився блок в чанку
+	ViewSectionBlocksUpdate(section [3]int32, changes []BlockChange)     // змінилось кілька блоків секції
+	ViewLightUpdate(pos level.ChunkPos, c *level.Chunk, sections uint32) // змінилось світло в секціях (біти)
 }
 
 // EntityViewer - інтерфейс для роботи з сутностями
@@ -51,10 +60,14 @@ type ChunkViewer interface {
 // появи, зникнення, руху, повороту голови тощо
 type EntityViewer interface {
 	ViewAddPlayer(p *Player)                                                      // додати гравця в зону видимості
+	ViewAddEntity(e *GenericEntity)                                               // додати іншу сутність в зону видимості
 	ViewRemoveEntities(entityIDs []int32)                                         // видалити сутності
 	ViewMoveEntityPos(id int32, delta [3]int16, onGround bool)                    // рух сутності
 	ViewMoveEntityPosAndRot(id int32, delta [3]int16, rot [2]int8, onGround bool) // рух + поворот
 	ViewMoveEntityRot(id int32, rot [2]int8, onGround bool)                       // поворот сутності
 	ViewRotateHead(id int32, yaw int8)                                            // поворот голови
 	ViewTeleportEntity(id int32, pos [3]float64, rot [2]int8, onGround bool)      // телепортація
+	ViewEntityData(id int32, data entity.MetadataSet)                             // метадані сутності
+	ViewAnimate(id int32, anim Animation)                                         // анімація, наприклад помах рукою
+	ViewEntityEvent(id int32, event EntityEvent)                                  // подія сутності, наприклад смерть
 }
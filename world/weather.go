@@ -0,0 +1,207 @@
+// Йоу, чат! Зараз розберемо як в світі йде дощ!
+// Погода живе в level.dat чотирма парами значень:
+// - raining / rainTime - чи йде дощ і через скільки тіків це зміниться
+// - thundering / thunderTime - те саме для грози
+// - clearWeatherTime - скільки ще тіків гарантовано ясно (після /weather clear)
+// Кожен тік таймери зменшуються, а коли доходять до нуля - погода перемикається
+// і таймер отримує нову випадкову тривалість, так само як у ванільному сервері.
+//
+// Клієнт не знає про таймери, він бачить тільки "силу" дощу і грози від 0 до 1.
+// Сила змінюється плавно на 0.01 за тік, тому дощ починається і закінчується поступово.
+// Всі зміни надсилаються пакетом ClientboundGameEvent.
+
+package world
+
+import "math/rand/v2"
+
+const (
+	ruleWeatherCycle = "doWeatherCycle" // правило гри, яке зупиняє зміну погоди
+
+	defaultWeatherDuration = 6000 // тривалість погоди для SetWeather без тривалості (5 хвилин)
+)
+
+// Тривалості погоди в тіках, як у ванілі: [min, max)
+var (
+	rainDelay       = [2]int32{12000, 180000} // скільки тіків ясно до наступного дощу
+	rainDuration    = [2]int32{12000, 24000}  // скільки тіків йде дощ
+	thunderDelay    = [2]int32{12000, 180000} // скільки тіків до наступної грози
+	thunderDuration = [2]int32{3600, 15600}   // скільки тіків триває гроза
+)
+
+// Події ClientboundGameEvent, які стосуються погоди
+const (
+	GameEventBeginRaining       = 1
+	GameEventEndRaining         = 2
+	GameEventRainLevelChange    = 7
+	GameEventThunderLevelChange = 8
+)
+
+// Weather - тип погоди
+type Weather int
+
+const (
+	WeatherClear   Weather = iota // ясно
+	WeatherRain                   // дощ
+	WeatherThunder                // гроза
+)
+
+// weatherState - сила дощу і грози, яку бачать клієнти
+// В level.dat не зберігається, а відновлюється з прапорців raining і thundering
+type weatherState struct {
+	rain    float32 // 0 - сухо, 1 - злива
+	thunder float32 // 0 - тихо, 1 - гроза
+}
+
+// initWeather встановлює силу погоди зі збереженого стану
+// Якщо світ зберегли під час дощу, після запуску дощ одразу йде на повну
+func (w *World) initWeather() {
+	if w.level.data.Raining {
+		w.weather.rain = 1
+		if w.level.data.Thundering {
+			w.weather.thunder = 1
+		}
+	}
+}
+
+// isRaining повертає true якщо клієнти бачать дощ
+// Поріг 0.2 взятий з ванільного Level.isRaining
+func (ws weatherState) isRaining() bool {
+	return ws.rain > 0.2
+}
+
+// sampleTicks повертає випадкову тривалість з діапазону
+func sampleTicks(r [2]int32) int32 {
+	return r[0] + rand.Int32N(r[1]-r[0])
+}
+
+// advanceWeather рахує один тік погоди і повідомляє гравців про зміни
+// Викликати тільки під tickLock
+func (w *World) advanceWeather() {
+	data := &w.level.data
+	wasRaining := w.weather.isRaining()
+
+	if w.boolRule(ruleWeatherCycle) {
+		if data.ClearWeatherTime > 0 {
+			// Примусово ясна погода: після неї таймери почнуть з нуля
+			data.ClearWeatherTime--
+			data.ThunderTime = boolInt(!data.Thundering)
+			data.RainTime = boolInt(!data.Raining)
+			data.Thundering, data.Raining = false, false
+		} else {
+			data.ThunderTime, data.Thundering = nextWeather(data.ThunderTime, data.Thundering, thunderDuration, thunderDelay)
+			data.RainTime, data.Raining = nextWeather(data.RainTime, data.Raining, rainDuration, rainDelay)
+		}
+	}
+
+	// Сила дощу і грози плавно йде до поточного стану
+	oldRain, oldThunder := w.weather.rain, w.weather.thunder
+	w.weather.thunder = approach(w.weather.thunder, data.Thundering)
+	w.weather.rain = approach(w.weather.rain, data.Raining)
+
+	switch {
+	case wasRaining != w.weather.isRaining():
+		event := GameEventBeginRaining
+		if wasRaining {
+			event = GameEventEndRaining
+		}
+		w.broadcastGameEvent(byte(event), 0)
+		w.broadcastGameEvent(GameEventRainLevelChange, w.weather.rain)
+		w.broadcastGameEvent(GameEventThunderLevelChange, w.weather.thunder)
+	default:
+		if oldRain != w.weather.rain {
+			w.broadcastGameEvent(GameEventRainLevelChange, w.weather.rain)
+		}
+		if oldThunder != w.weather.thunder {
+			w.broadcastGameEvent(GameEventThunderLevelChange, w.weather.thunder)
+		}
+	}
+}
+
+// nextWeather зменшує таймер погоди і перемикає її, коли таймер закінчився
+// Нульовий таймер означає, що тривалість ще не обрана
+func nextWeather(timer int32, active bool, duration, delay [2]int32) (int32, bool) {
+	switch {
+	case timer > 0:
+		timer--
+		if timer == 0 {
+			active = !active
+		}
+	case active:
+		timer = sampleTicks(duration)
+	default:
+		timer = sampleTicks(delay)
+	}
+	return timer, active
+}
+
+// approach змінює силу погоди на 0.01 в бік увімкненого чи вимкненого стану
+func approach(level float32, on bool) float32 {
+	if on {
+		return min(level+0.01, 1)
+	}
+	return max(level-0.01, 0)
+}
+
+// boolInt перетворює bool в 1 або 0
+func boolInt(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// broadcastGameEvent відправляє подію всім гравцям світу
+// Викликати тільки під tickLock
+func (w *World) broadcastGameEvent(event byte, value float32) {
+	for c := range w.players {
+		c.SendGameEvent(event, value)
+	}
+}
+
+// sendWeather відправляє новому гравцю поточну погоду
+// Викликати тільки під tickLock
+func (w *World) sendWeather(c Client) {
+	if !w.weather.isRaining() {
+		return
+	}
+	c.SendGameEvent(GameEventBeginRaining, 0)
+	c.SendGameEvent(GameEventRainLevelChange, w.weather.rain)
+	c.SendGameEvent(GameEventThunderLevelChange, w.weather.thunder)
+}
+
+// Weather повертає поточну погоду з level.dat
+func (w *World) Weather() Weather {
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	switch data := &w.level.data; {
+	case data.Thundering && data.Raining:
+		return WeatherThunder
+	case data.Raining:
+		return WeatherRain
+	}
+	return WeatherClear
+}
+
+// SetWeather вмикає погоду на duration тіків, як команда /weather
+// duration <= 0 означає 6000 тіків. Після цього погода знову змінюється сама,
+// якщо не вимкнене правило doWeatherCycle
+// Сила дощу змінюється плавно, тому клієнти побачать зміну за кілька секунд
+func (w *World) SetWeather(weather Weather, duration int32) {
+	if duration <= 0 {
+		duration = defaultWeatherDuration
+	}
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	data := &w.level.data
+	switch weather {
+	case WeatherClear:
+		data.ClearWeatherTime, data.RainTime, data.ThunderTime = duration, 0, 0
+		data.Raining, data.Thundering = false, false
+	case WeatherRain:
+		data.ClearWeatherTime, data.RainTime, data.ThunderTime = 0, duration, duration
+		data.Raining, data.Thundering = true, false
+	case WeatherThunder:
+		data.ClearWeatherTime, data.RainTime, data.ThunderTime = 0, duration, duration
+		data.Raining, data.Thundering = true, true
+	}
+}
@@ -0,0 +1,65 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/save"
+)
+
+func TestWeather(t *testing.T) {
+	c := &fakeClient{}
+	w := &World{
+		level:   &Level{data: save.LevelData{GameRules: map[string]string{}}},
+		players: map[Client]*Player{c: {}},
+	}
+
+	w.SetWeather(WeatherRain, 150)
+	if w.Weather() != WeatherRain {
+		t.Fatalf("weather = %v, want rain", w.Weather())
+	}
+	for i := 0; i < 149; i++ {
+		w.advanceWeather()
+	}
+	if c.count(GameEventBeginRaining) != 1 || c.rain != 1 || !w.level.data.Raining {
+		t.Fatalf("rain did not start: events %v, rain level %v", c.gameEvents, c.rain)
+	}
+
+	// Новий гравець одразу бачить дощ
+	joined := &fakeClient{}
+	w.sendWeather(joined)
+	if joined.count(GameEventBeginRaining) != 1 || joined.rain != 1 {
+		t.Errorf("joined player got %v", joined.gameEvents)
+	}
+
+	// Тривалість закінчилась: дощ стихає, а таймер отримує нову тривалість
+	w.advanceWeather()
+	if w.level.data.Raining || w.Weather() != WeatherClear {
+		t.Fatal("rain did not stop after the forced duration")
+	}
+	for i := 0; i < 100; i++ {
+		w.advanceWeather()
+	}
+	if c.count(GameEventEndRaining) != 1 || c.rain != 0 {
+		t.Errorf("rain did not end: events %v, rain level %v", c.gameEvents, c.rain)
+	}
+	if rt := w.level.data.RainTime; rt < rainDelay[0]-100 || rt >= rainDelay[1] {
+		t.Errorf("new rain delay %d is out of range", rt)
+	}
+
+	// Без doWeatherCycle таймери стоять
+	w.level.data.GameRules[ruleWeatherCycle] = "false"
+	rt := w.level.data.RainTime
+	w.advanceWeather()
+	if w.level.data.RainTime != rt {
+		t.Error("weather timers run with doWeatherCycle=false")
+	}
+
+	// Примусово ясна погода не дає почати дощ
+	w.level.data.GameRules[ruleWeatherCycle] = "true"
+	w.SetWeather(WeatherClear, 10)
+	w.level.data.Raining = true
+	w.advanceWeather()
+	if w.level.data.Raining || w.level.data.ClearWeatherTime != 9 {
+		t.Errorf("clear weather: raining %v, clear time %d", w.level.data.Raining, w.level.data.ClearWeatherTime)
+	}
+}
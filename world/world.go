@@ -24,39 +24,73 @@ package world
 
 import (
 	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/time/rate"
 
 	"FlowyCore/world/internal/bvh"
 	"github.com/Tnze/go-mc/level"
-	"github.com/Tnze/go-mc/level/block"
 )
 
 // World - головна структура, що представляє ігровий світ
 // Містить всі компоненти для роботи світу та керування ним
 type World struct {
-	log           *zap.Logger   // логер для відлагодження
-	config        Config        // конфігурація світу
-	chunkProvider ChunkProvider // провайдер для завантаження чанків
+	log           *zap.Logger    // логер для відлагодження
+	config        Config         // конфігурація світу
+	chunkProvider *ChunkProvider // провайдер для завантаження чанків
+	level         *Level         // живий стан рівня з level.dat
+	seed          int64          // сід світу для генератора
 
-	chunks   map[[2]int32]*LoadedChunk // завантажені чанки
-	loaders  map[ChunkViewer]*loader   // завантажувачі чанків для гравців
-	tickLock sync.Mutex                // м'ютекс для синхронізації тіків
+	chunks       map[[2]int32]*LoadedChunk // завантажені чанки
+	chunksLock   sync.RWMutex              // мапа chunks змінюється під Lock, читається з інших горутин під RLock
+	chunkLoader  *chunkLoadPool            // фонове завантаження чанків
+	loaders      map[ChunkViewer]*loader   // завантажувачі чанків для гравців
+	tickLock     sync.Mutex                // м'ютекс для синхронізації тіків
+	closed       bool                      // світ закрито, тіки більше не виконуються
+	tickStop     chan struct{}             // закривається в Close, щоб зупинити tickLoop
+	tickStats    tickStats                 // швидкість тіків для TickStats
+	profiler     tickProfiler              // час фаз тіку і звіти про повільні тіки
+	scheduler    scheduler                 // задачі, які виконуються в тік-горутині
+	weather      weatherState              // сила дощу і грози, яку бачать клієнти
+	regionCount  int                       // скільки регіонів було в минулому тіку
+	blockUpdates blockUpdates              // зміни блоків, які ще не розіслані гравцям
+	lightChanged map[[2]int32]uint32       // секції (біти), світло в яких змінилось за тік
 
 	// playerViews - BVH дерево для зберігання зон видимості гравців
 	// Використовується для швидкого визначення, яким гравцям надсилати
 	// сповіщення про рух сутностей
 	playerViews playerViewTree
-	players     map[Client]*Player // активні гравці
+	players     map[Client]*Player       // активні гравці
+	entities    map[int32]*GenericEntity // сутності, які не є гравцями
 }
 
 // Config - налаштування світу
+// Точка спавну, час, погода і т.д. живуть в Level, бо їх треба зберігати
 type Config struct {
-	ViewDistance  int32    // радіус прогрузки в чанках
-	SpawnAngle    float32  // кут повороту при спавні
-	SpawnPosition [3]int32 // координати точки спавну
+	ViewDistance int32 // радіус прогрузки в чанках
+
+	// ChunkLoadWorkers - скільки чанків можна завантажувати з диску одночасно
+	ChunkLoadWorkers int
+	// ChunkLoadLimiter - скільки нових чанків на секунду можна віддати воркерам
+	// nil = без обмежень
+	ChunkLoadLimiter *rate.Limiter
+	// Generator - генератор нових чанків, nil = StoneGenerator
+	Generator Generator
+
+	// TPS - скільки тіків на секунду виконувати, 0 = DefaultTPS
+	TPS int
+	// MaxCatchUpTicks - на скільки тіків світ може відстати від розкладу.
+	// Менше відставання наздоганяється тіками підряд, більше - відкидається
+	// 0 = DefaultMaxCatchUpTicks
+	MaxCatchUpTicks int
+
+	// RegionThreads - скільки регіонів гравців тікати паралельно
+	// 0 або 1 = всі гравці тікаються по черзі в одній горутині
+	RegionThreads int
 }
 
 // playerView - структура для зберігання інформації про видимість гравця
@@ -74,16 +108,34 @@ type (
 )
 
 // New створює новий світ з вказаними параметрами
-func New(logger *zap.Logger, provider ChunkProvider, config Config) (w *World) {
+// level - стан рівня, завантажений через LoadLevel
+func New(logger *zap.Logger, provider *ChunkProvider, level *Level, config Config) (w *World) {
+	if config.Generator == nil {
+		config.Generator = StoneGenerator{}
+	}
+	if g, ok := config.Generator.(levelGenerator); ok {
+		level.setOverworldGenerator(g.levelSettings())
+	}
 	w = &World{
 		log:           logger,
 		config:        config,
+		level:         level,
+		seed:          level.Seed(),
 		chunks:        make(map[[2]int32]*LoadedChunk),
 		loaders:       make(map[ChunkViewer]*loader),
 		players:       make(map[Client]*Player),
+		entities:      make(map[int32]*GenericEntity),
 		chunkProvider: provider,
+		tickStop:      make(chan struct{}),
 	}
-	go w.tickLoop() // запускаємо цикл оновлення світу
+	w.initWeather()
+	w.chunkLoader = w.startChunkLoadPool(config.ChunkLoadWorkers, config.ChunkLoadLimiter)
+	sched := newTickScheduler(config.TPS, config.MaxCatchUpTicks, time.Now())
+	w.tickStats.target = float64(time.Second / sched.interval)
+	w.profiler.budget = sched.interval
+	// Раз на секунду нагадуємо клієнтам котра година
+	w.RunRepeating(timeSyncInterval, timeSyncInterval, w.broadcastTime)
+	go w.tickLoop(sched) // запускаємо цикл оновлення світу
 	return
 }
 
@@ -94,12 +146,16 @@ func (w *World) Name() string {
 
 // SpawnPositionAndAngle повертає координати та кут спавну
 func (w *World) SpawnPositionAndAngle() ([3]int32, float32) {
-	return w.config.SpawnPosition, w.config.SpawnAngle
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	data := &w.level.data
+	return [3]int32{data.SpawnX, data.SpawnY, data.SpawnZ}, data.SpawnAngle
 }
 
 // HashedSeed повертає хеш сіда світу
+// Клієнт використовує його щоб згладжувати кольори біомів так само, як ванільний клієнт
 func (w *World) HashedSeed() [8]byte {
-	return [8]byte{}
+	return hashSeed(w.seed)
 }
 
 // AddPlayer додає гравця до світу
@@ -107,9 +163,18 @@ func (w *World) HashedSeed() [8]byte {
 func (w *World) AddPlayer(c Client, p *Player, limiter *rate.Limiter) {
 	w.tickLock.Lock()
 	defer w.tickLock.Unlock()
+	// Поки клієнт не надіслав свою позицію, вважаємо що він стоїть там,
+	// де ми його заспавнили. Інакше нульові Inputs перемістять гравця в (0, 0, 0)
+	p.pos0, p.rot0 = p.Position, p.Rotation
+	p.Inputs.Lock()
+	p.Inputs.Position, p.Inputs.Rotation, p.Inputs.OnGround = p.Position, p.Rotation, p.OnGround
+	p.Inputs.Unlock()
 	w.loaders[c] = newLoader(p, limiter)
 	w.players[c] = p
 	p.view = w.playerViews.Insert(p.getView(), playerView{c, p})
+	// Новий гравець одразу отримує поточний час і погоду, а не чекає синхронізації
+	c.SendSetTime(w.worldTime())
+	w.sendWeather(c)
 }
 
 // RemovePlayer видаляє гравця зі світу
@@ -141,59 +206,28 @@ func (w *World) RemovePlayer(c Client, p *Player) {
 	)
 }
 
-// loadChunk завантажує чанк за вказаними координатами
-// Якщо чанк не існує - генерує новий порожній чанк
-// Повертає:
-// - true якщо чанк успішно завантажено або згенеровано
-// - false якщо виникла помилка або досягнуто ліміт завантаження
-func (w *World) loadChunk(pos [2]int32) bool {
-	// Створюємо логер з координатами чанку для зручного дебагу
-	logger := w.log.With(zap.Int32("x", pos[0]), zap.Int32("z", pos[1]))
-	logger.Debug("Loading chunk")
-
-	// Намагаємось завантажити чанк через провайдер
-	c, err := w.chunkProvider.GetChunk(pos)
-	if err != nil {
-		if errors.Is(err, errChunkNotExist) {
-			// Чанк не існує - генеруємо новий
-			logger.Debug("Generate chunk")
-
-			// ТИМЧАСОВО: Створюємо порожній чанк заповнений камінням
-			// TODO: Додати нормальний генератор світу
-			c = level.EmptyChunk(24)                // 24 секції по висоті (384 блоки)
-			stone := block.ToStateID[block.Stone{}] // ID блоку каменю
-			for s := range c.Sections {             // для кожної секції (16x16x16 блоків)
-				for i := 0; i < 16*16*16; i++ { // заповнюємо всі блоки
-					c.Sections[s].SetBlock(i, stone)
-				}
-			}
-			c.Status = level.StatusFull // позначаємо чанк як повністю згенерований
-			logger.Debug("Created empty chunk", zap.Int("sections", len(c.Sections)))
-
-		} else if !errors.Is(err, ErrReachRateLimit) {
-			// Якщо помилка не пов'язана з лімітом завантаження - логуємо її
-			logger.Error("GetChunk error", zap.Error(err))
-			return false
-		}
+// SavePlayers зберігає всіх гравців світу через провайдер
+// Знімки стану робляться під tickLock, а файли пишуться вже після його звільнення,
+// щоб повільний диск не гальмував тіки. Якщо гравець тим часом вийде,
+// PlayerProvider не дасть цьому знімку перезаписати збереження при виході
+func (w *World) SavePlayers(provider *PlayerProvider) error {
+	w.tickLock.Lock()
+	states := make([]playerState, 0, len(w.players))
+	for _, p := range w.players {
+		states = append(states, p.state())
 	}
+	w.tickLock.Unlock()
 
-	// Перевіряємо що чанк не nil після завантаження/генерації
-	if c == nil {
-		logger.Error("Chunk is nil after loading")
-		return false
+	var errs []error
+	for _, s := range states {
+		if err := provider.savePlayerState(s); err != nil {
+			errs = append(errs, fmt.Errorf("save player %v fail: %w", s.UUID, err))
+		}
 	}
-
-	// Логуємо успішне завантаження
-	logger.Debug("Loaded chunk",
-		zap.Int("sections", len(c.Sections)),
-		zap.String("status", string(c.Status)))
-
-	// Зберігаємо чанк в мапі завантажених чанків
-	w.chunks[pos] = &LoadedChunk{Chunk: c}
-	return true
+	return errors.Join(errs...)
 }
 
-// unloadChunk вивантажує чанк та зберігає його
+// unloadChunk вивантажує чанк та зберігає його, якщо він був змінений
 func (w *World) unloadChunk(pos [2]int32) {
 	logger := w.log.With(zap.Int32("x", pos[0]), zap.Int32("z", pos[1]))
 	logger.Debug("Unloading chunk")
@@ -206,20 +240,90 @@ func (w *World) unloadChunk(pos [2]int32) {
 		viewer.ViewChunkUnload(pos)
 	}
 	// Зберігаємо чанк через провайдер
-	err := w.chunkProvider.PutChunk(pos, c.Chunk)
+	// SetBlock з інших горутин чекає, щоб зміна не загубилась між збереженням і видаленням
+	w.chunksLock.Lock()
+	defer w.chunksLock.Unlock()
+	w.saveChunk(pos, c)
+	delete(w.chunks, pos)
+}
+
+// SaveChunks записує на диск всі змінені завантажені чанки
+// Викликається при автозбереженні та при зупинці сервера,
+// бо чанки навколо гравців можуть не вивантажуватись годинами
+func (w *World) SaveChunks() {
+	w.tickLock.Lock()
+	defer w.tickLock.Unlock()
+	for pos, lc := range w.chunks {
+		w.saveChunk(pos, lc)
+	}
+	w.flushChunks()
+}
+
+// flushChunks скидає на диск регіони, в які були записані чанки
+// Один виклик на пачку чанків, щоб не переписувати файл регіону для кожного чанку
+func (w *World) flushChunks() {
+	if err := w.chunkProvider.Flush(); err != nil {
+		w.log.Error("Flush regions error", zap.Error(err))
+	}
+}
+
+// Close зберігає всі змінені чанки і закриває файли регіонів
+// Після Close світом більше не можна користуватись
+func (w *World) Close() error {
+	// Зупиняємо тіки і воркерів, щоб після збереження ніхто не чіпав чанки
+	w.tickLock.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.chunkLoader.done)
+		close(w.tickStop)
+	}
+	w.tickLock.Unlock()
+
+	w.SaveChunks()
+	return w.chunkProvider.Close()
+}
+
+// saveChunk записує чанк на диск, але тільки якщо він змінений
+// Незмінені чанки вже лежать в .mca файлі, тому писати їх вдруге немає сенсу
+func (w *World) saveChunk(pos [2]int32, lc *LoadedChunk) {
+	if !lc.dirty.Swap(false) {
+		return
+	}
+	lc.Lock()
+	err := w.chunkProvider.PutChunk(pos, lc.Chunk)
+	lc.Unlock()
 	if err != nil {
-		logger.Error("Store chunk data error", zap.Error(err))
+		// Не вдалося зберегти - залишаємо чанк зміненим, спробуємо ще раз пізніше
+		lc.MarkDirty()
+		w.log.Error("Store chunk data error",
+			zap.Int32("x", pos[0]),
+			zap.Int32("z", pos[1]),
+			zap.Error(err))
 	}
-	delete(w.chunks, pos)
 }
 
 // LoadedChunk - структура завантаженого чанку
 type LoadedChunk struct {
 	sync.Mutex                 // м'ютекс для синхронізації
 	viewers      []ChunkViewer // список спостерігачів
+	dirty        atomic.Bool   // чи змінений чанк з моменту останнього збереження
+	requested    atomic.Bool   // чанк читали чи змінювали через GetBlock/SetBlock
+	keepAlive    int           // скільки ще перевірок вивантаження чанк живе без спостерігачів
 	*level.Chunk               // дані чанку
 }
 
+// MarkDirty позначає чанк зміненим
+// Такий чанк буде записаний на диск при вивантаженні
+// Викликайте після кожної зміни блоків чи інших даних чанку
+func (lc *LoadedChunk) MarkDirty() {
+	lc.dirty.Store(true)
+}
+
+// IsDirty повертає true якщо чанк має незбережені зміни
+func (lc *LoadedChunk) IsDirty() bool {
+	return lc.dirty.Load()
+}
+
 // AddViewer додає нового спостерігача до чанку
 // ВАЖЛИВО: Метод панікує якщо спостерігач вже існує!
 // Це зроблено для виявлення логічних помилок в коді,